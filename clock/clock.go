@@ -0,0 +1,205 @@
+//go:build go1.15
+// +build go1.15
+
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package clock provides a virtual clock which patches the functions of the time package by mockey.Mock, so the tests
+// involving time can be driven without waiting.
+//
+// Example:
+//
+//	mockey.PatchConvey("timeout", t, func() {
+//		clk := clock.Mock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), mockey.OptExcludeCurrentGoRoutine())
+//		go worker() // calls time.Sleep(time.Minute)
+//		clk.BlockUntilSleepers(1)
+//		clk.Advance(time.Minute) // the worker wakes up
+//	})
+//
+// The time only moves by Advance and Set, so the goroutines waiting on the clock block until the test moves it. Use
+// BlockUntilSleepers to make sure they are waiting before moving the time.
+//
+// The package requires go1.15 or later, which introduced (*time.Ticker).Reset.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bytedance/mockey"
+)
+
+// Clock is a virtual clock, whose time only changes by Advance and Set
+type Clock struct {
+	lock    sync.Mutex
+	cond    *sync.Cond // broadcast when a waiter is scheduled
+	now     time.Time
+	waiters []*waiter // scheduled waiters ordered by deadline
+	timers  map[*time.Timer]*waiter
+	tickers map[*time.Ticker]*waiter
+	mockers []*mockey.Mocker
+}
+
+// waiter is a pending Sleep, timer or ticker
+type waiter struct {
+	deadline time.Time
+	period   time.Duration // interval of a ticker, 0 for others
+	fire     func(now time.Time)
+}
+
+// Mock creates a clock starting at start and patches time.Now, time.Since, time.Until, time.Sleep, time.After,
+// time.AfterFunc, time.NewTimer and time.NewTicker onto it. The goroutine options apply to these functions, so the
+// goroutines filtered out keep using the real time, see mockey.GoroutineOpt.
+//
+// The methods of the timers and tickers created by the clock are patched too, so they must not be used after the clock
+// is unpatched.
+func Mock(start time.Time, opts ...mockey.GoroutineOpt) *Clock {
+	c := &Clock{
+		now:     start.Round(0), // strip the monotonic clock reading
+		timers:  make(map[*time.Timer]*waiter),
+		tickers: make(map[*time.Ticker]*waiter),
+	}
+	c.cond = sync.NewCond(&c.lock)
+
+	mock := func(target, hook interface{}) {
+		builder := mockey.Mock(target).To(hook).FilterGoRoutineOpts(opts...)
+		c.mockers = append(c.mockers, builder.RecordCalls(0).Build())
+	}
+	mock(time.Now, c.Now)
+	mock(time.Since, func(t time.Time) time.Duration { return c.Now().Sub(t) })
+	mock(time.Until, func(t time.Time) time.Duration { return t.Sub(c.Now()) })
+	mock(time.Sleep, c.sleep)
+	mock(time.After, func(d time.Duration) <-chan time.Time { return c.newTimer(d).C })
+	mock(time.AfterFunc, c.afterFunc)
+	mock(time.NewTimer, c.newTimer)
+	mock(time.NewTicker, c.newTicker)
+
+	// the methods only take effect on the timers and tickers of the clock, regardless of the goroutine
+	ownTimer := func(t *time.Timer) bool {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		return c.timers[t] != nil
+	}
+	ownTicker := func(t *time.Ticker) bool {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		return c.tickers[t] != nil
+	}
+	mockMethod := func(target, when, hook interface{}) {
+		c.mockers = append(c.mockers, mockey.Mock(target).When(when).To(hook).RecordCalls(0).Build())
+	}
+	mockMethod((*time.Timer).Stop, ownTimer, c.stopTimer)
+	mockMethod((*time.Timer).Reset, func(t *time.Timer, d time.Duration) bool { return ownTimer(t) }, c.resetTimer)
+	mockMethod((*time.Ticker).Stop, ownTicker, c.stopTicker)
+	mockMethod((*time.Ticker).Reset, func(t *time.Ticker, d time.Duration) bool { return ownTicker(t) }, c.resetTicker)
+	return c
+}
+
+// UnPatch stops the clock early and restores the real time, the timers and tickers of the clock must not be used then
+func (c *Clock) UnPatch() {
+	for i := len(c.mockers) - 1; i >= 0; i-- {
+		c.mockers[i].UnPatch()
+	}
+}
+
+// Now returns the current time of the clock
+func (c *Clock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d, and fires the sleepers, timers and tickers whose deadlines are reached in the
+// order of their deadlines.
+func (c *Clock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.advanceTo(c.now.Add(d))
+}
+
+// Set sets the time of the clock. If t is after the current time, it fires the sleepers, timers and tickers like
+// Advance, otherwise nothing is fired.
+func (c *Clock) Set(t time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.advanceTo(t.Round(0))
+	c.now = t.Round(0)
+}
+
+// BlockUntilSleepers blocks until there are at least n pending sleepers, including the calls of time.Sleep and the
+// active timers and tickers of the clock. It's usually called before Advance to make sure the goroutines under test
+// are waiting.
+func (c *Clock) BlockUntilSleepers(n int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+func (c *Clock) advanceTo(t time.Time) {
+	for len(c.waiters) > 0 && !c.waiters[0].deadline.After(t) {
+		w := c.waiters[0]
+		c.waiters = c.waiters[1:]
+		if w.deadline.After(c.now) {
+			c.now = w.deadline
+		}
+		w.fire(c.now)
+		if w.period > 0 {
+			c.schedule(w, w.deadline.Add(w.period))
+		}
+	}
+	if t.After(c.now) {
+		c.now = t
+	}
+}
+
+// schedule adds the waiter with the deadline, the waiter is fired at once if the deadline has been reached
+func (c *Clock) schedule(w *waiter, deadline time.Time) {
+	w.deadline = deadline
+	if !deadline.After(c.now) && w.period == 0 {
+		w.fire(c.now)
+		return
+	}
+	i := sort.Search(len(c.waiters), func(i int) bool { return c.waiters[i].deadline.After(deadline) })
+	c.waiters = append(c.waiters, nil)
+	copy(c.waiters[i+1:], c.waiters[i:])
+	c.waiters[i] = w
+	c.cond.Broadcast()
+}
+
+// unschedule removes the waiter and returns whether it was scheduled
+func (c *Clock) unschedule(w *waiter) bool {
+	for i := range c.waiters {
+		if c.waiters[i] == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Clock) sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	done := make(chan struct{})
+	c.lock.Lock()
+	c.schedule(&waiter{fire: func(time.Time) { close(done) }}, c.now.Add(d))
+	c.lock.Unlock()
+	<-done
+}
@@ -0,0 +1,104 @@
+//go:build go1.15
+// +build go1.15
+
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bytedance/mockey"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockey.PatchConvey("TestClock", t, func() {
+		mockey.PatchConvey("now", func() {
+			clk := Mock(start)
+			So(time.Now(), ShouldEqual, start)
+			clk.Advance(time.Hour)
+			So(time.Since(start), ShouldEqual, time.Hour)
+			So(time.Until(start), ShouldEqual, -time.Hour)
+			clk.Set(start)
+			So(time.Now(), ShouldEqual, start)
+		})
+		mockey.PatchConvey("sleep", func() {
+			clk := Mock(start)
+			done := make(chan time.Time)
+			go func() {
+				time.Sleep(time.Minute)
+				done <- time.Now()
+			}()
+			clk.BlockUntilSleepers(1)
+			clk.Advance(time.Minute)
+			So(<-done, ShouldEqual, start.Add(time.Minute))
+		})
+		mockey.PatchConvey("timer", func() {
+			clk := Mock(start)
+			timer := time.NewTimer(time.Second)
+			after := time.After(2 * time.Second)
+			fired := make(chan struct{})
+			time.AfterFunc(3*time.Second, func() { close(fired) })
+			So(len(timer.C), ShouldEqual, 0)
+
+			clk.Advance(time.Second)
+			So(<-timer.C, ShouldEqual, start.Add(time.Second))
+			So(len(after), ShouldEqual, 0)
+			So(timer.Reset(time.Second), ShouldBeFalse)
+			So(timer.Stop(), ShouldBeTrue)
+
+			clk.Advance(2 * time.Second)
+			So(<-after, ShouldEqual, start.Add(2*time.Second))
+			So(len(timer.C), ShouldEqual, 0)
+			<-fired
+		})
+		mockey.PatchConvey("ticker", func() {
+			clk := Mock(start)
+			ticker := time.NewTicker(time.Second)
+			clk.Advance(time.Second)
+			So(<-ticker.C, ShouldEqual, start.Add(time.Second))
+			clk.Advance(3 * time.Second)
+			So(<-ticker.C, ShouldEqual, start.Add(2*time.Second)) // the later ticks are dropped
+			ticker.Reset(time.Minute)
+			clk.Advance(time.Second)
+			So(len(ticker.C), ShouldEqual, 0)
+			ticker.Stop()
+			clk.Advance(time.Hour)
+			So(len(ticker.C), ShouldEqual, 0)
+		})
+		mockey.PatchConvey("goroutine filter", func() {
+			Mock(start, mockey.OptExcludeCurrentGoRoutine())
+			So(time.Now().After(start), ShouldBeTrue)
+			now := make(chan time.Time)
+			go func() { now <- time.Now() }()
+			So(<-now, ShouldEqual, start)
+		})
+		mockey.PatchConvey("real timers", func() {
+			timer := time.NewTimer(time.Hour)
+			Mock(start)
+			So(timer.Stop(), ShouldBeTrue)
+			So(timer.Reset(time.Hour), ShouldBeFalse)
+			So(timer.Stop(), ShouldBeTrue)
+		})
+	})
+	if time.Now().Before(start) || mockey.IsMocked(time.Now) {
+		t.Error("clock is not unpatched")
+	}
+}
@@ -0,0 +1,102 @@
+//go:build go1.15
+// +build go1.15
+
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clock
+
+import (
+	"time"
+)
+
+// sendTime sends the time to the channel without blocking, the time is dropped if the channel is full, which is the
+// same as the timers and tickers of the time package
+func sendTime(ch chan time.Time) func(now time.Time) {
+	return func(now time.Time) {
+		select {
+		case ch <- now:
+		default:
+		}
+	}
+}
+
+func (c *Clock) newTimer(d time.Duration) *time.Timer {
+	ch := make(chan time.Time, 1)
+	t := &time.Timer{C: ch}
+	c.addTimer(t, &waiter{fire: sendTime(ch)}, d)
+	return t
+}
+
+func (c *Clock) afterFunc(d time.Duration, f func()) *time.Timer {
+	t := &time.Timer{}
+	c.addTimer(t, &waiter{fire: func(time.Time) { go f() }}, d)
+	return t
+}
+
+func (c *Clock) addTimer(t *time.Timer, w *waiter, d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.timers[t] = w
+	c.schedule(w, c.now.Add(d))
+}
+
+func (c *Clock) stopTimer(t *time.Timer) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.unschedule(c.timers[t])
+}
+
+func (c *Clock) resetTimer(t *time.Timer, d time.Duration) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	w := c.timers[t]
+	active := c.unschedule(w)
+	c.schedule(w, c.now.Add(d))
+	return active
+}
+
+func (c *Clock) newTicker(d time.Duration) *time.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	ch := make(chan time.Time, 1)
+	t := &time.Ticker{C: ch}
+	w := &waiter{period: d, fire: sendTime(ch)}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.tickers[t] = w
+	c.schedule(w, c.now.Add(d))
+	return t
+}
+
+func (c *Clock) stopTicker(t *time.Ticker) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.unschedule(c.tickers[t])
+}
+
+func (c *Clock) resetTicker(t *time.Ticker, d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	w := c.tickers[t]
+	c.unschedule(w)
+	w.period = d
+	c.schedule(w, c.now.Add(d))
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/bytedance/mockey/internal/tool"
+)
+
+var (
+	// ErrNotFunction means the target, hook or condition is not a function
+	ErrNotFunction = errors.New("not a function")
+	// ErrSignatureMismatch means the when/to/origin/return values do not match the signature of the target
+	ErrSignatureMismatch = errors.New("signature mismatch")
+	// ErrTargetTooShort means the target function is too short to patch
+	ErrTargetTooShort = errors.New("function is too short to patch")
+	// ErrAlreadyMocked means the target has already been mocked in a concurrent scope, such as a parallel test, or the
+	// variable has already been mocked in the current scope
+	ErrAlreadyMocked = errors.New("already mocked")
+	// ErrInvalidUsage means the API is misused, such as setting the hook of a condition twice
+	ErrInvalidUsage = errors.New("invalid usage")
+)
+
+// MockError describes a failure of mocking. Use errors.Is to check its kind, for example:
+//
+//	_, err := Mock(Fun).To(func() {}).TryBuild()
+//	errors.Is(err, ErrSignatureMismatch) // true
+type MockError struct {
+	Kind   error         // one of the ErrXXX above, nil if the failure is unexpected
+	Msg    string        // detail of the failure
+	Target string        // name of the mock target
+	Caller runtime.Frame // where the failing API is called
+}
+
+func (e *MockError) Error() string {
+	return fmt.Sprintf("%s, target: %s, at: %s:%d", e.Msg, e.Target, e.Caller.File, e.Caller.Line)
+}
+
+func (e *MockError) Unwrap() error {
+	return e.Kind
+}
+
+func newMockError(kind error, format string, args ...interface{}) *MockError {
+	return &MockError{Kind: kind, Msg: fmt.Sprintf(format, args...)}
+}
+
+// catch runs f and converts its panic into a MockError of the given kind
+func catch(kind error, f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = newMockError(kind, "%v", r)
+		}
+	}()
+	f()
+	return nil
+}
+
+var (
+	failureHandler     = defaultFailureHandler
+	failureHandlerLock sync.RWMutex
+)
+
+func defaultFailureHandler(err error) {
+	panic(err.Error())
+}
+
+// SetFailureHandler sets the handler of the failures reported by Build, Patch and the re-mock APIs of Mocker, and
+// returns the previous one. The default handler panics with the error message, nil restores it.
+//
+// For example, fail the test instead of panicking:
+//
+//	defer SetFailureHandler(SetFailureHandler(FatalHandler(t)))
+func SetFailureHandler(handler func(err error)) (previous func(err error)) {
+	if handler == nil {
+		handler = defaultFailureHandler
+	}
+	failureHandlerLock.Lock()
+	defer failureHandlerLock.Unlock()
+	previous, failureHandler = failureHandler, handler
+	return previous
+}
+
+// FatalHandler returns a failure handler which reports the failure by t.Fatalf
+func FatalHandler(t testing.TB) func(err error) {
+	return func(err error) {
+		t.Helper()
+		t.Fatalf("mockey: %v", err)
+	}
+}
+
+func handleFailure(err error) {
+	if err == nil {
+		return
+	}
+	failureHandlerLock.RLock()
+	handler := failureHandler
+	failureHandlerLock.RUnlock()
+	handler(err)
+}
+
+// stampError fills the target and caller of the MockError
+func (builder *MockBuilder) stampError(err error) error {
+	var mErr *MockError
+	if errors.As(err, &mErr) && mErr.Target == "" {
+		mErr.Target = builder.name()
+		mErr.Caller = runtime.Frame(tool.OuterCaller())
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+//go:build go1.17
+// +build go1.17
+
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestTryBuildTooShort(t *testing.T) {
+	PatchConvey("TestTryBuildTooShort", t, func() {
+		_, err := Mock(ShortFun).To(func() {}).TryBuild()
+		convey.So(errors.Is(err, ErrTargetTooShort), convey.ShouldBeTrue)
+		convey.So(errors.Is(Mock(ShortFun).Validate(), ErrTargetTooShort), convey.ShouldBeTrue)
+		convey.So(MockUnsafe(ShortFun).Validate(), convey.ShouldBeNil)
+	})
+}
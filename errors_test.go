@@ -0,0 +1,96 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bytedance/mockey/internal/tool"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestTryBuild(t *testing.T) {
+	PatchConvey("TestTryBuild", t, func() {
+		PatchConvey("success", func() {
+			convey.So(Mock(Fun).Return("mocked").Validate(), convey.ShouldBeNil)
+			mocker, err := Mock(Fun).Return("mocked").TryBuild()
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(Fun("a"), convey.ShouldEqual, "mocked")
+			convey.So(mocker.Times(), convey.ShouldEqual, 1)
+		})
+		PatchConvey("not function", func() {
+			_, err := Mock(1).Return("mocked").TryBuild()
+			convey.So(errors.Is(err, ErrNotFunction), convey.ShouldBeTrue)
+			_, err = Mock(Fun).To("mocked").TryBuild()
+			convey.So(errors.Is(err, ErrNotFunction), convey.ShouldBeTrue)
+		})
+		PatchConvey("signature mismatch", func() {
+			mocker, err := Mock(Fun).To(func(a string) int { return 0 }).TryBuild()
+			caller := tool.Caller()
+			caller.Line -= 1
+			convey.So(mocker, convey.ShouldBeNil)
+			convey.So(errors.Is(err, ErrSignatureMismatch), convey.ShouldBeTrue)
+			var mErr *MockError
+			convey.So(errors.As(err, &mErr), convey.ShouldBeTrue)
+			convey.So(mErr.Target, convey.ShouldEqual, "github.com/bytedance/mockey.Fun")
+			convey.So(mErr.Caller.File, convey.ShouldEqual, caller.File)
+			convey.So(mErr.Caller.Line, convey.ShouldEqual, caller.Line)
+			convey.So(Fun("a"), convey.ShouldEqual, "a")
+
+			convey.So(errors.Is(Mock(Fun).When(func(a int) bool { return true }).Validate(), ErrSignatureMismatch), convey.ShouldBeTrue)
+			convey.So(errors.Is(Mock(Fun).Return(1, 2).Validate(), ErrSignatureMismatch), convey.ShouldBeTrue)
+			origin := MultiReturn
+			convey.So(errors.Is(Mock(Fun).Origin(&origin).Validate(), ErrSignatureMismatch), convey.ShouldBeTrue)
+		})
+		PatchConvey("invalid usage", func() {
+			err := Mock(Fun).Return("a").Return("b").Validate()
+			convey.So(errors.Is(err, ErrInvalidUsage), convey.ShouldBeTrue)
+		})
+		PatchConvey("already mocked", func() {
+			v := 1
+			MockValue(&v).To(2)
+			convey.So(func() { MockValue(&v).To(3) }, convey.ShouldPanic)
+			Mock(Fun).Return("mocked").Build()
+			convey.So(Mock(Fun).Return("mocked").Validate(), convey.ShouldBeNil)
+		})
+	})
+}
+
+func TestFailureHandler(t *testing.T) {
+	PatchConvey("TestFailureHandler", t, func() {
+		var failures []error
+		previous := SetFailureHandler(func(err error) { failures = append(failures, err) })
+		defer SetFailureHandler(previous)
+
+		mocker := Mock(Fun).To(func(a int) string { return "" }).Build()
+		convey.So(failures, convey.ShouldHaveLength, 1)
+		convey.So(errors.Is(failures[0], ErrSignatureMismatch), convey.ShouldBeTrue)
+		convey.So(mocker.Times(), convey.ShouldEqual, 0)
+		convey.So(Fun("a"), convey.ShouldEqual, "a")
+
+		mocker = Mock(Fun).Return("mocked").Build()
+		mocker.Return(1, 2)
+		convey.So(failures, convey.ShouldHaveLength, 2)
+		convey.So(errors.Is(failures[1], ErrSignatureMismatch), convey.ShouldBeTrue)
+		convey.So(strings.Contains(failures[1].Error(), "github.com/bytedance/mockey.Fun"), convey.ShouldBeTrue)
+
+		SetFailureHandler(nil)
+		convey.So(func() { Mock(Fun).To(func(a int) string { return "" }).Build() }, convey.ShouldPanic)
+	})
+}
@@ -20,6 +20,11 @@
 package iface
 
 import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+
 	"github.com/bytedance/mockey"
 	"github.com/bytedance/mockey/exp/iface/internal"
 	"github.com/bytedance/mockey/internal/tool"
@@ -28,9 +33,12 @@ import (
 type Mocker struct {
 	builder *MockBuilder
 	mockers []*mockey.Mocker
+
+	outerCaller tool.CallerInfo
 }
 
 type MockBuilder struct {
+	name     string // name of the interface method
 	builders []*mockey.MockBuilder
 }
 
@@ -49,7 +57,7 @@ type MockBuilder struct {
 func Mock(target interface{}, opt ...OptionFn) *MockBuilder {
 	opts := resolveOpt(opt...)
 	targets := internal.FindImplementTargets(target, opts.selector)
-	builder := &MockBuilder{}
+	builder := &MockBuilder{name: runtime.FuncForPC(reflect.ValueOf(target).Pointer()).Name()}
 	tool.DebugPrintf("[InterfaceMock] start to mock for %d targets...\n", len(targets))
 	for i, t := range targets {
 		builder.builders = append(builder.builders, mockey.Mock(t))
@@ -82,7 +90,7 @@ func (builder *MockBuilder) Return(results ...interface{}) *MockBuilder {
 
 func (builder *MockBuilder) Build() *Mocker {
 	tool.DebugPrintf("[InterfaceMock] start to build for %d targets...\n", len(builder.builders))
-	mocker := Mocker{builder: builder}
+	mocker := Mocker{builder: builder, outerCaller: tool.OuterCaller()}
 	for i, b := range builder.builders {
 		mocker.mockers = append(mocker.mockers, b.Build())
 		tool.DebugPrintf("[InterfaceMock] mocker generated for index: %d\n", i+1)
@@ -126,3 +134,25 @@ func (mocker *Mocker) MockTimes() int {
 	}
 	return res
 }
+
+// Verify checks the total times the implemented methods are called, see mockey.Mocker.Verify.
+func (mocker *Mocker) Verify(t testing.TB, opt mockey.CountOpt) bool {
+	t.Helper()
+	return mocker.verify(t, "called", mocker.Times(), opt)
+}
+
+// VerifyMock checks the total times the mock hooks are executed, see mockey.Mocker.VerifyMock.
+func (mocker *Mocker) VerifyMock(t testing.TB, opt mockey.CountOpt) bool {
+	t.Helper()
+	return mocker.verify(t, "mocked", mocker.MockTimes(), opt)
+}
+
+func (mocker *Mocker) verify(t testing.TB, action string, times int, opt mockey.CountOpt) bool {
+	t.Helper()
+	if opt.Match(times) {
+		return true
+	}
+	name := fmt.Sprintf("%v(%d targets)", mocker.builder.name, len(mocker.mockers))
+	t.Errorf("mockey: %s", tool.CountFailure(name, action, opt, times, mocker.Times(), mocker.MockTimes(), mocker.outerCaller))
+	return false
+}
@@ -419,3 +419,34 @@ func TestMockInterface_Selector(t *testing.T) {
 		})
 	})
 }
+
+func TestMockInterface_Verify(t *testing.T) {
+	mockey.PatchConvey("TestMockInterface_Verify", t, func() {
+		impl1 := &MyIImpl1{inner: "12"}
+		impl3 := MyIImpl3{}
+
+		mocker := Mock(MyI.Foo1).Return("MOCKED!").Build()
+		CallFoo(impl1, "anything")
+		CallFoo(impl3, "anything")
+
+		So(mocker.Verify(t, mockey.Exactly(2)), ShouldBeTrue)
+		So(mocker.VerifyMock(t, mockey.AtLeast(1)), ShouldBeTrue)
+
+		tb := &recordTB{TB: t}
+		So(mocker.Verify(tb, mockey.Exactly(1)), ShouldBeFalse)
+		So(tb.errors, ShouldHaveLength, 1)
+		So(tb.errors[0], ShouldContainSubstring, "targets) expected to be called exactly 1 time, but called 2 times (called 2 times, mocked 2 times)")
+	})
+}
+
+// recordTB records the failures instead of failing the test
+type recordTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordTB) Helper() {}
+
+func (r *recordTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,310 @@
+//go:build go1.16
+// +build go1.16
+
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fsmock
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"time"
+
+	"github.com/bytedance/mockey"
+)
+
+// file is the state of a file opened in the sandbox
+type file struct {
+	name      string // name passed to open
+	path      string // path in the sandbox
+	node      *node
+	flag      int
+	offset    int64
+	dirOffset int // count of the directory entries read
+	closed    bool
+}
+
+// mockFileMethods patches the methods of os.File, which only take effect on the files opened in the sandbox
+func (s *FS) mockFileMethods() {
+	s.mockMethod((*os.File).Read, s.read)
+	s.mockMethod((*os.File).ReadAt, s.readAt)
+	s.mockMethod((*os.File).Write, s.write)
+	s.mockMethod((*os.File).WriteAt, s.writeAt)
+	s.mockMethod((*os.File).WriteString, func(f *os.File, str string) (int, error) { return s.write(f, []byte(str)) })
+	s.mockMethod((*os.File).ReadFrom, func(f *os.File, r io.Reader) (int64, error) { return io.Copy(fileWriter{s, f}, r) })
+	s.mockMethod((*os.File).Seek, s.seek)
+	s.mockMethod((*os.File).Close, s.close)
+	s.mockMethod((*os.File).Stat, s.statFile)
+	s.mockMethod((*os.File).Name, s.name)
+	s.mockMethod((*os.File).ReadDir, s.readDirFile)
+	s.mockMethod((*os.File).Readdir, s.readdir)
+	s.mockMethod((*os.File).Readdirnames, s.readdirnames)
+	s.mockMethod((*os.File).Sync, func(f *os.File) error { _, err := s.file(f, "sync"); return err })
+	s.mockMethod((*os.File).Truncate, s.truncate)
+	s.mockWriteTo()
+}
+
+// mockMethod patches the method of os.File with hook, which takes effect when the receiver is opened in the sandbox.
+// The calls are not recorded like mock.
+func (s *FS) mockMethod(target, hook interface{}) {
+	typ := reflect.TypeOf(target)
+	in := make([]reflect.Type, typ.NumIn())
+	for i := range in {
+		in[i] = typ.In(i)
+	}
+	whenType := reflect.FuncOf(in, []reflect.Type{reflect.TypeOf(true)}, typ.IsVariadic())
+	when := reflect.MakeFunc(whenType, func(args []reflect.Value) []reflect.Value {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		return []reflect.Value{reflect.ValueOf(s.files[args[0].Interface().(*os.File)] != nil)}
+	})
+	s.mockers = append(s.mockers, mockey.Mock(target).When(when.Interface()).To(hook).RecordCalls(0).Build())
+}
+
+// file returns the state of the opened file f, or the error if it has been closed
+func (s *FS) file(f *os.File, op string) (*file, error) {
+	h := s.files[f]
+	if h.closed {
+		return nil, &fs.PathError{Op: op, Path: h.name, Err: os.ErrClosed}
+	}
+	return h, nil
+}
+
+func (s *FS) read(f *os.File, b []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	h, err := s.file(f, "read")
+	if err != nil {
+		return 0, err
+	}
+	n, err := h.readAt(b, h.offset)
+	h.offset += int64(n)
+	return n, err
+}
+
+func (s *FS) readAt(f *os.File, b []byte, off int64) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	h, err := s.file(f, "read")
+	if err != nil {
+		return 0, err
+	}
+	if off < 0 {
+		return 0, &fs.PathError{Op: "readat", Path: h.name, Err: errors.New("negative offset")}
+	}
+	n, err := h.readAt(b, off)
+	if err == nil && n < len(b) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (h *file) readAt(b []byte, off int64) (int, error) {
+	if h.node.mode.IsDir() {
+		return 0, &fs.PathError{Op: "read", Path: h.name, Err: syscall.EISDIR}
+	}
+	if h.flag&os.O_WRONLY != 0 {
+		return 0, &fs.PathError{Op: "read", Path: h.name, Err: syscall.EBADF}
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	if off >= int64(len(h.node.data)) {
+		return 0, io.EOF
+	}
+	return copy(b, h.node.data[off:]), nil
+}
+
+func (s *FS) write(f *os.File, b []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	h, err := s.file(f, "write")
+	if err != nil {
+		return 0, err
+	}
+	if h.flag&os.O_APPEND != 0 {
+		h.offset = int64(len(h.node.data))
+	}
+	n, err := h.writeAt(b, h.offset)
+	h.offset += int64(n)
+	return n, err
+}
+
+func (s *FS) writeAt(f *os.File, b []byte, off int64) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	h, err := s.file(f, "write")
+	if err != nil {
+		return 0, err
+	}
+	if h.flag&os.O_APPEND != 0 {
+		return 0, errors.New("os: invalid use of WriteAt on file opened with O_APPEND")
+	}
+	if off < 0 {
+		return 0, &fs.PathError{Op: "writeat", Path: h.name, Err: errors.New("negative offset")}
+	}
+	return h.writeAt(b, off)
+}
+
+func (h *file) writeAt(b []byte, off int64) (int, error) {
+	if h.flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		return 0, &fs.PathError{Op: "write", Path: h.name, Err: syscall.EBADF}
+	}
+	if end := off + int64(len(b)); end > int64(len(h.node.data)) {
+		h.node.data = append(h.node.data, make([]byte, end-int64(len(h.node.data)))...)
+	}
+	h.node.modTime = time.Now()
+	return copy(h.node.data[off:], b), nil
+}
+
+func (s *FS) seek(f *os.File, offset int64, whence int) (int64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	h, err := s.file(f, "seek")
+	if err != nil {
+		return 0, err
+	}
+	switch whence {
+	case io.SeekCurrent:
+		offset += h.offset
+	case io.SeekEnd:
+		offset += int64(len(h.node.data))
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: h.name, Err: syscall.EINVAL}
+	}
+	h.offset = offset
+	return offset, nil
+}
+
+func (s *FS) close(f *os.File) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	h, err := s.file(f, "close")
+	if err != nil {
+		return err
+	}
+	h.closed = true
+	return nil
+}
+
+func (s *FS) statFile(f *os.File) (fs.FileInfo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	h, err := s.file(f, "stat")
+	if err != nil {
+		return nil, err
+	}
+	return h.node.info(filepath.Base(h.path)), nil
+}
+
+func (s *FS) name(f *os.File) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.files[f].name
+}
+
+func (s *FS) readDirFile(f *os.File, n int) ([]fs.DirEntry, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	h, err := s.file(f, "readdirent")
+	if err != nil {
+		return nil, err
+	}
+	if !h.node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdirent", Path: h.name, Err: syscall.ENOTDIR}
+	}
+	entries := s.entries(h.path)
+	if h.dirOffset < len(entries) {
+		entries = entries[h.dirOffset:]
+	} else {
+		entries = nil
+	}
+	if n > 0 {
+		if len(entries) == 0 {
+			return nil, io.EOF
+		}
+		if len(entries) > n {
+			entries = entries[:n]
+		}
+	}
+	h.dirOffset += len(entries)
+	return entries, nil
+}
+
+func (s *FS) readdir(f *os.File, n int) ([]fs.FileInfo, error) {
+	entries, err := s.readDirFile(f, n)
+	infos := make([]fs.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, _ := entry.Info()
+		infos = append(infos, info)
+	}
+	return infos, err
+}
+
+func (s *FS) readdirnames(f *os.File, n int) ([]string, error) {
+	entries, err := s.readDirFile(f, n)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names, err
+}
+
+func (s *FS) truncate(f *os.File, size int64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	h, err := s.file(f, "truncate")
+	if err != nil {
+		return err
+	}
+	if size < 0 || h.flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		return &fs.PathError{Op: "truncate", Path: h.name, Err: syscall.EINVAL}
+	}
+	if size <= int64(len(h.node.data)) {
+		h.node.data = h.node.data[:size]
+	} else {
+		h.node.data = append(h.node.data, make([]byte, size-int64(len(h.node.data)))...)
+	}
+	h.node.modTime = time.Now()
+	return nil
+}
+
+// fileWriter writes to a file in the sandbox without the ReadFrom method, so io.Copy won't call File.ReadFrom again
+type fileWriter struct {
+	s *FS
+	f *os.File
+}
+
+func (w fileWriter) Write(b []byte) (int, error) {
+	return w.s.write(w.f, b)
+}
+
+// fileReader reads a file in the sandbox without the WriteTo method, so io.Copy won't call File.WriteTo again
+type fileReader struct {
+	s *FS
+	f *os.File
+}
+
+func (r fileReader) Read(b []byte) (int, error) {
+	return r.s.read(r.f, b)
+}
@@ -0,0 +1,30 @@
+//go:build go1.22
+// +build go1.22
+
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fsmock
+
+import (
+	"io"
+	"os"
+)
+
+// mockWriteTo patches File.WriteTo, which is added in go1.22
+func (s *FS) mockWriteTo() {
+	s.mockMethod((*os.File).WriteTo, func(f *os.File, w io.Writer) (int64, error) { return io.Copy(w, fileReader{s, f}) })
+}
@@ -0,0 +1,357 @@
+//go:build go1.16
+// +build go1.16
+
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package fsmock provides an in-memory filesystem sandbox, which patches the functions of the os package by
+// mockey.Mock to redirect the paths under the chosen prefixes, so the code accessing fixed paths such as /etc/... can be
+// tested without touching the real disk.
+//
+// Example:
+//
+//	mockey.PatchConvey("config", t, func() {
+//		fsmock.Mock(fstest.MapFS{"etc/app/config.yaml": {Data: []byte("debug: true")}}, "/etc/app")
+//		data, err := os.ReadFile("/etc/app/config.yaml") // debug: true
+//	})
+//
+// Only the paths under the prefixes are sandboxed, the others still reach the real disk, so the fixtures in memory can
+// be mixed with the real files such as the testdata directory.
+//
+// The package requires go1.16 or later, which introduced io/fs and testing/fstest.
+package fsmock
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"testing/fstest"
+	"time"
+
+	"github.com/bytedance/mockey"
+	"github.com/bytedance/mockey/internal/tool"
+)
+
+// FS is an in-memory filesystem sandbox
+type FS struct {
+	lock     sync.Mutex
+	wd       string   // working directory to resolve the relative paths, fixed when the sandbox is created
+	prefixes []string // cleaned absolute paths of the redirected directories
+	nodes    map[string]*node
+	files    map[*os.File]*file
+	mockers  []*mockey.Mocker
+}
+
+// node is a file or directory in the sandbox
+type node struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// Mock creates a sandbox seeded from seed and patches os.Open, os.OpenFile, os.ReadFile, os.WriteFile, os.Stat,
+// os.ReadDir, os.MkdirAll and os.Remove. The paths under prefixes are redirected to the sandbox, and the others are
+// passed through to the origin functions.
+//
+// The paths in seed are relative to the root directory, e.g. "etc/app/config.yaml" is /etc/app/config.yaml, and they
+// must be under prefixes. The files opened in the sandbox are placeholders of *os.File whose methods are patched, so
+// they can't be used where the file descriptor is required, such as File.Fd.
+func Mock(seed fstest.MapFS, prefixes ...string) *FS {
+	wd, err := os.Getwd()
+	tool.Assert(err == nil, "fsmock: get working directory failed: %v", err)
+	s := &FS{
+		wd:    wd,
+		nodes: make(map[string]*node),
+		files: make(map[*os.File]*file),
+	}
+	for _, prefix := range prefixes {
+		p := s.abs(prefix)
+		s.prefixes = append(s.prefixes, p)
+		s.nodes[p] = &node{mode: fs.ModeDir | 0o755}
+	}
+	root := string(filepath.Separator)
+	for name, f := range seed {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		tool.Assert(s.sandboxed(p), "fsmock: %s is not under the prefixes %v", p, prefixes)
+		s.mkdirAll(filepath.Dir(p), 0o755)
+		n := &node{data: append([]byte(nil), f.Data...), mode: f.Mode, modTime: f.ModTime}
+		if n.mode.IsDir() {
+			n.data = nil
+		}
+		s.nodes[p] = n
+	}
+	s.mockFunctions()
+	s.mockFileMethods()
+	return s
+}
+
+// UnPatch removes the sandbox early, the paths under the prefixes reach the real disk again
+func (s *FS) UnPatch() {
+	for i := len(s.mockers) - 1; i >= 0; i-- {
+		s.mockers[i].UnPatch()
+	}
+}
+
+func (s *FS) mockFunctions() {
+	var open func(name string) (*os.File, error)
+	s.mock(os.Open, &open, func(name string) (*os.File, error) {
+		if p, ok := s.lookup(name); ok {
+			return s.openFile(name, p, os.O_RDONLY, 0)
+		}
+		return open(name)
+	})
+	var openFile func(name string, flag int, perm fs.FileMode) (*os.File, error)
+	s.mock(os.OpenFile, &openFile, func(name string, flag int, perm fs.FileMode) (*os.File, error) {
+		if p, ok := s.lookup(name); ok {
+			return s.openFile(name, p, flag, perm)
+		}
+		return openFile(name, flag, perm)
+	})
+	var readFile func(name string) ([]byte, error)
+	s.mock(os.ReadFile, &readFile, func(name string) ([]byte, error) {
+		if p, ok := s.lookup(name); ok {
+			return s.readFile(name, p)
+		}
+		return readFile(name)
+	})
+	var writeFile func(name string, data []byte, perm fs.FileMode) error
+	s.mock(os.WriteFile, &writeFile, func(name string, data []byte, perm fs.FileMode) error {
+		if p, ok := s.lookup(name); ok {
+			return s.writeFile(name, p, data, perm)
+		}
+		return writeFile(name, data, perm)
+	})
+	var stat func(name string) (fs.FileInfo, error)
+	s.mock(os.Stat, &stat, func(name string) (fs.FileInfo, error) {
+		if p, ok := s.lookup(name); ok {
+			return s.stat(name, p)
+		}
+		return stat(name)
+	})
+	var readDir func(name string) ([]fs.DirEntry, error)
+	s.mock(os.ReadDir, &readDir, func(name string) ([]fs.DirEntry, error) {
+		if p, ok := s.lookup(name); ok {
+			return s.readDir(name, p)
+		}
+		return readDir(name)
+	})
+	var mkdirAll func(path string, perm fs.FileMode) error
+	s.mock(os.MkdirAll, &mkdirAll, func(path string, perm fs.FileMode) error {
+		if p, ok := s.lookup(path); ok {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			if err := s.mkdirAll(p, perm); err != nil {
+				return &fs.PathError{Op: "mkdir", Path: path, Err: err}
+			}
+			return nil
+		}
+		return mkdirAll(path, perm)
+	})
+	var remove func(name string) error
+	s.mock(os.Remove, &remove, func(name string) error {
+		if p, ok := s.lookup(name); ok {
+			return s.remove(name, p)
+		}
+		return remove(name)
+	})
+}
+
+// mock patches target with hook, and origin is set to the origin function. The calls are not recorded, so that the
+// arguments, such as the buffers to write, are not retained by the mockers.
+func (s *FS) mock(target, origin, hook interface{}) {
+	s.mockers = append(s.mockers, mockey.Mock(target).Origin(origin).To(hook).RecordCalls(0).Build())
+}
+
+// abs returns the cleaned absolute path of name without calling os.Getwd, which calls the patched os.Stat
+func (s *FS) abs(name string) string {
+	if filepath.IsAbs(name) {
+		return filepath.Clean(name)
+	}
+	return filepath.Join(s.wd, name)
+}
+
+func (s *FS) sandboxed(p string) bool {
+	for _, prefix := range s.prefixes {
+		if p == prefix || strings.HasPrefix(p, strings.TrimSuffix(prefix, string(filepath.Separator))+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the path of name in the sandbox, and whether name is redirected to the sandbox
+func (s *FS) lookup(name string) (string, bool) {
+	p := s.abs(name)
+	return p, s.sandboxed(p)
+}
+
+// checkParent checks the parent of p is an existing directory, s.lock must be held
+func (s *FS) checkParent(p string) error {
+	parent, ok := s.nodes[filepath.Dir(p)]
+	if !ok || !s.sandboxed(filepath.Dir(p)) {
+		return fs.ErrNotExist
+	}
+	if !parent.mode.IsDir() {
+		return syscall.ENOTDIR
+	}
+	return nil
+}
+
+// mkdirAll creates the directory p and its missing parents in the sandbox, s.lock must be held
+func (s *FS) mkdirAll(p string, perm fs.FileMode) error {
+	if n, ok := s.nodes[p]; ok {
+		if !n.mode.IsDir() {
+			return syscall.ENOTDIR
+		}
+		return nil
+	}
+	if !s.sandboxed(p) {
+		return fs.ErrNotExist
+	}
+	if err := s.mkdirAll(filepath.Dir(p), perm); err != nil {
+		return err
+	}
+	s.nodes[p] = &node{mode: fs.ModeDir | perm&fs.ModePerm, modTime: time.Now()}
+	return nil
+}
+
+func (s *FS) openFile(name, p string, flag int, perm fs.FileMode) (*os.File, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	n, ok := s.nodes[p]
+	writable := flag&(os.O_WRONLY|os.O_RDWR) != 0
+	switch {
+	case !ok && flag&os.O_CREATE == 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	case !ok:
+		if err := s.checkParent(p); err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		n = &node{mode: perm & fs.ModePerm, modTime: time.Now()}
+		s.nodes[p] = n
+	case flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	case n.mode.IsDir() && writable:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: syscall.EISDIR}
+	case flag&os.O_TRUNC != 0 && writable:
+		n.data, n.modTime = nil, time.Now()
+	}
+	f := new(os.File)
+	s.files[f] = &file{name: name, node: n, flag: flag, path: p}
+	return f, nil
+}
+
+func (s *FS) readFile(name, p string) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	n, ok := s.nodes[p]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if n.mode.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: syscall.EISDIR}
+	}
+	return append([]byte{}, n.data...), nil
+}
+
+func (s *FS) writeFile(name, p string, data []byte, perm fs.FileMode) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	n, ok := s.nodes[p]
+	if !ok {
+		if err := s.checkParent(p); err != nil {
+			return &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		n = &node{mode: perm & fs.ModePerm}
+		s.nodes[p] = n
+	} else if n.mode.IsDir() {
+		return &fs.PathError{Op: "open", Path: name, Err: syscall.EISDIR}
+	}
+	n.data, n.modTime = append([]byte(nil), data...), time.Now()
+	return nil
+}
+
+func (s *FS) stat(name, p string) (fs.FileInfo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	n, ok := s.nodes[p]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return n.info(filepath.Base(p)), nil
+}
+
+func (s *FS) readDir(name, p string) ([]fs.DirEntry, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	n, ok := s.nodes[p]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if !n.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdirent", Path: name, Err: syscall.ENOTDIR}
+	}
+	return s.entries(p), nil
+}
+
+// entries returns the entries of the directory p sorted by name, s.lock must be held
+func (s *FS) entries(p string) []fs.DirEntry {
+	var entries []fs.DirEntry
+	for q, n := range s.nodes {
+		if q != p && filepath.Dir(q) == p {
+			entries = append(entries, fs.FileInfoToDirEntry(n.info(filepath.Base(q))))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries
+}
+
+func (s *FS) remove(name, p string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	n, ok := s.nodes[p]
+	if !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if n.mode.IsDir() && len(s.entries(p)) > 0 {
+		return &fs.PathError{Op: "remove", Path: name, Err: syscall.ENOTEMPTY}
+	}
+	delete(s.nodes, p)
+	return nil
+}
+
+// info returns a snapshot of the file information
+func (n *node) info(name string) fs.FileInfo {
+	return &fileInfo{name: name, size: int64(len(n.data)), mode: n.mode, modTime: n.modTime}
+}
+
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (fi *fileInfo) Name() string       { return fi.name }
+func (fi *fileInfo) Size() int64        { return fi.size }
+func (fi *fileInfo) Mode() fs.FileMode  { return fi.mode }
+func (fi *fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *fileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi *fileInfo) Sys() interface{}   { return nil }
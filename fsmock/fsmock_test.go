@@ -0,0 +1,110 @@
+//go:build go1.16
+// +build go1.16
+
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fsmock
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/bytedance/mockey"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFS(t *testing.T) {
+	seed := fstest.MapFS{
+		"etc/mockey/app.conf":      {Data: []byte("debug=true")},
+		"etc/mockey/conf.d/a.conf": {Data: []byte("a")},
+	}
+	mockey.PatchConvey("TestFS", t, func() {
+		Mock(seed, "/etc/mockey")
+
+		mockey.PatchConvey("read", func() {
+			data, err := os.ReadFile("/etc/mockey/app.conf")
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "debug=true")
+
+			f, err := os.Open("/etc/mockey/conf.d/a.conf")
+			So(err, ShouldBeNil)
+			data, err = io.ReadAll(f)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "a")
+			So(f.Name(), ShouldEqual, "/etc/mockey/conf.d/a.conf")
+			So(f.Close(), ShouldBeNil)
+			So(errors.Is(f.Close(), os.ErrClosed), ShouldBeTrue)
+
+			_, err = os.ReadFile("/etc/mockey/missing")
+			So(errors.Is(err, fs.ErrNotExist), ShouldBeTrue)
+		})
+		mockey.PatchConvey("write", func() {
+			So(os.WriteFile("/etc/mockey/new.conf", []byte("new"), 0o644), ShouldBeNil)
+			f, err := os.OpenFile("/etc/mockey/new.conf", os.O_WRONLY|os.O_APPEND, 0)
+			So(err, ShouldBeNil)
+			_, err = f.WriteString("er")
+			So(err, ShouldBeNil)
+			So(f.Close(), ShouldBeNil)
+			data, err := os.ReadFile("/etc/mockey/new.conf")
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "newer")
+
+			info, err := os.Stat("/etc/mockey/new.conf")
+			So(err, ShouldBeNil)
+			So(info.Size(), ShouldEqual, 5)
+			So(info.Mode(), ShouldEqual, fs.FileMode(0o644))
+
+			err = os.WriteFile("/etc/mockey/missing/new.conf", nil, 0o644)
+			So(errors.Is(err, fs.ErrNotExist), ShouldBeTrue)
+		})
+		mockey.PatchConvey("directory", func() {
+			So(os.MkdirAll("/etc/mockey/b/c", 0o755), ShouldBeNil)
+			entries, err := os.ReadDir("/etc/mockey")
+			So(err, ShouldBeNil)
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			So(names, ShouldResemble, []string{"app.conf", "b", "conf.d"})
+
+			So(os.Remove("/etc/mockey/b"), ShouldNotBeNil)
+			So(os.Remove("/etc/mockey/b/c"), ShouldBeNil)
+			So(os.Remove("/etc/mockey/b"), ShouldBeNil)
+			_, err = os.Stat("/etc/mockey/b")
+			So(errors.Is(err, fs.ErrNotExist), ShouldBeTrue)
+		})
+		mockey.PatchConvey("pass through", func() {
+			dir := t.TempDir()
+			name := filepath.Join(dir, "real.conf")
+			So(os.WriteFile(name, []byte("real"), 0o644), ShouldBeNil)
+			data, err := os.ReadFile(name)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "real")
+			f, err := os.Open(name)
+			So(err, ShouldBeNil)
+			So(f.Close(), ShouldBeNil)
+		})
+	})
+	if _, err := os.Stat("/etc/mockey"); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("sandbox is not unpatched")
+	}
+}
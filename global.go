@@ -17,32 +17,205 @@
 package mockey
 
 import (
+	"fmt"
+	"os"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
 
 	"github.com/bytedance/mockey/internal/tool"
 	"github.com/smartystreets/goconvey/convey"
 )
 
-var gMocker = make([]map[uintptr]mockerInstance, 0)
+// mockScope is the scope of the mocks created in a PatchConvey, PatchRun or PatchT context, which is owned by the
+// goroutine creating it
+type mockScope struct {
+	parent  *mockScope
+	gid     int64            // goroutine owning the scope, 0 for the root scope
+	name    string           // name of the test for the scope of PatchT
+	mockers []mockerInstance // mockers in creation order
+}
+
+var (
+	gLock       sync.Mutex // guards the scopes and serializes the registration of mockers
+	gRootScope  = &mockScope{}
+	gScopes     = []*mockScope{gRootScope} // open scopes in creation order
+	gLeakChecks []*leakCheck               // running checks of VerifyNoLeaksT
+)
 
-func init() {
-	gMocker = append(gMocker, make(map[uintptr]mockerInstance))
+// leakCheck is the check of VerifyNoLeaksT, which collects the mocks added to the root scope by the goroutine of the
+// test and its descendants
+type leakCheck struct {
+	gid     int64
+	mockers []mockerInstance
+}
+
+// currentScope returns the scope of the current goroutine, see ownScope. If the ancestors of the goroutine are
+// unknown, such as before go1.21 or for the goroutines created before the scopes, it returns the innermost open scope.
+func currentScope() *mockScope {
+	if scope := ownScope(); scope != nil {
+		return scope
+	}
+	return gScopes[len(gScopes)-1]
 }
 
-func addToGlobal(mocker mockerInstance) {
+// ownScope returns the innermost open scope owned by the current goroutine. If there is none, it returns the innermost
+// scope owned by the nearest ancestor goroutine, see tool.IsGoroutineDescendant, or nil.
+func ownScope() *mockScope {
+	gid := tool.GetGoroutineID()
+	for i := len(gScopes) - 1; i > 0; i-- {
+		if gScopes[i].gid == gid {
+			return gScopes[i]
+		}
+	}
+	for i := len(gScopes) - 1; i > 0; i-- {
+		if tool.IsGoroutineDescendant(gid, gScopes[i].gid) {
+			return gScopes[i]
+		}
+	}
+	return nil
+}
+
+// testScope returns the innermost open scope of the parent tests of the test name, or the root scope
+func testScope(name string) *mockScope {
+	for i := len(gScopes) - 1; i > 0; i-- {
+		if s := gScopes[i]; s.name != "" && strings.HasPrefix(name, s.name+"/") {
+			return s
+		}
+	}
+	return gRootScope
+}
+
+// lookup returns the last mocker of the target of key in the scope
+func (scope *mockScope) lookup(key uintptr) mockerInstance {
+	for i := len(scope.mockers) - 1; i >= 0; i-- {
+		if scope.mockers[i].key() == key {
+			return scope.mockers[i]
+		}
+	}
+	return nil
+}
+
+// conflictOf returns the failure if the target of key has been mocked in an open scope which is not an ancestor of
+// scope, such as the scope of a parallel test. If the mock is not layered, mocking the target twice in scope fails too.
+func conflictOf(scope *mockScope, key uintptr, layered bool) error {
+	if last := scope.lookup(key); last != nil && !layered {
+		return newMockError(ErrAlreadyMocked, "re-mock %v, previous mock at: %v", last.name(), last.caller())
+	}
+	ancestors := map[*mockScope]bool{}
+	for s := scope; s != nil; s = s.parent {
+		ancestors[s] = true
+	}
+	for _, s := range gScopes {
+		if last := s.lookup(key); last != nil && !ancestors[s] {
+			return newMockError(ErrAlreadyMocked, "%v is mocked in a concurrent context, previous mock at: %v", last.name(), last.caller())
+		}
+	}
+	return nil
+}
+
+// checkGlobal returns the failure if the target of key can't be mocked in the current scope by Mock
+func checkGlobal(key uintptr) error {
+	gLock.Lock()
+	defer gLock.Unlock()
+	return conflictOf(currentScope(), key, true)
+}
+
+// addToGlobal runs patch and adds the mocker to the current scope atomically. It fails without running patch if the
+// target can't be mocked in the current scope.
+func addToGlobal(mocker mockerInstance, patch func() error) error {
+	gLock.Lock()
+	defer gLock.Unlock()
 	key := mocker.key()
+	scope := currentScope()
+	_, layered := mocker.(*Mocker)
+	if err := conflictOf(scope, key, layered); err != nil {
+		return err
+	}
+	if err := patch(); err != nil {
+		return err
+	}
 	tool.DebugPrintf("[addToGlobal] 0x%x added\n", key)
-	last, ok := gMocker[len(gMocker)-1][key]
-	if ok {
-		tool.Assert(!ok, "re-mock %v, previous mock at: %v", last.name(), last.caller())
+	scope.mockers = append(scope.mockers, mocker)
+	if scope == gRootScope {
+		gid := tool.GetGoroutineID()
+		for _, check := range gLeakChecks {
+			if tool.IsGoroutineDescendant(gid, check.gid) {
+				check.mockers = append(check.mockers, mocker)
+			}
+		}
 	}
-	gMocker[len(gMocker)-1][key] = mocker
+	return nil
 }
 
 func removeFromGlobal(mocker mockerInstance) {
+	gLock.Lock()
+	defer gLock.Unlock()
 	key := mocker.key()
 	tool.DebugPrintf("[removeFromGlobal] 0x%x removed\n", key)
-	delete(gMocker[len(gMocker)-1], key)
+	for _, s := range gScopes {
+		for i, m := range s.mockers {
+			if m == mocker {
+				s.mockers = append(s.mockers[:i:i], s.mockers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// pushScope opens a new scope owned by the current goroutine. The scope of PatchT is named by the test, so that it's
+// nested in the scope of the parent test even if the goroutine of the test has unknown ancestors, and it's not nested
+// in the scopes of the concurrent tests.
+func pushScope(name string) *mockScope {
+	gLock.Lock()
+	defer gLock.Unlock()
+	parent := ownScope()
+	if parent == nil && name != "" {
+		parent = testScope(name)
+	}
+	if parent == nil {
+		parent = gScopes[len(gScopes)-1]
+	}
+	scope := &mockScope{parent: parent, gid: tool.GetGoroutineID(), name: name}
+	gScopes = append(gScopes, scope)
+	return scope
+}
+
+// popScope closes the scope and unpatches all mocks in it. If the scope finished normally, the expectations of the
+// mocks are checked and the unmet ones are returned.
+func popScope(scope *mockScope, finished bool) (unmet []string) {
+	gLock.Lock()
+	for i, s := range gScopes {
+		if s == scope {
+			gScopes = append(gScopes[:i:i], gScopes[i+1:]...)
+		} else if s.parent == scope {
+			s.parent = scope.parent
+		}
+	}
+	mockers := scope.mockers
+	scope.mockers = nil
+	gLock.Unlock()
+
+	// unpatch the upper layers first
+	for i := len(mockers) - 1; i >= 0; i-- {
+		mocker := mockers[i]
+		if finished {
+			if msg := mocker.unmetExpectation(); msg != "" {
+				unmet = append(unmet, msg)
+			}
+		}
+		mocker.unPatch()
+	}
+	sort.Strings(unmet)
+	return unmet
+}
+
+// popScopeOrPanic is like popScope, but the unmet expectations cause a panic after all mocks are unpatched.
+func popScopeOrPanic(scope *mockScope, finished bool) {
+	unmet := popScope(scope, finished)
+	tool.Assert(len(unmet) == 0, "unmet expectations:\n%s", strings.Join(unmet, "\n"))
 }
 
 // PatchConvey creates a test context that automatically manages mock lifecycles.
@@ -52,6 +225,7 @@ func removeFromGlobal(mocker mockerInstance) {
 // - No need to manually manage mock cleanup with defer statements
 // - Supports nested contexts, where each level only cleans up its own mocks
 // - Ensures proper mock isolation between test cases
+// - Checks the expectations declared by MockBuilder.Expect when the context ends
 //
 // Usage examples:
 //
@@ -79,14 +253,12 @@ func PatchConvey(items ...interface{}) {
 	for i, item := range items {
 		if reflect.TypeOf(item).Kind() == reflect.Func {
 			items[i] = reflect.MakeFunc(reflect.TypeOf(item), func(args []reflect.Value) []reflect.Value {
-				gMocker = append(gMocker, make(map[uintptr]mockerInstance))
-				defer func() {
-					for _, mocker := range gMocker[len(gMocker)-1] {
-						mocker.unPatch()
-					}
-					gMocker = gMocker[:len(gMocker)-1]
-				}()
-				return tool.ReflectCall(reflect.ValueOf(item), args)
+				scope := pushScope("")
+				finished := false
+				defer func() { popScopeOrPanic(scope, finished) }()
+				res := tool.ReflectCall(reflect.ValueOf(item), args)
+				finished = true
+				return res
 			}).Interface()
 		}
 	}
@@ -100,6 +272,7 @@ func PatchConvey(items ...interface{}) {
 // - No need to manually manage mock cleanup with defer statements
 // - Supports nested contexts, where each level only cleans up its own mocks
 // - More lightweight than PatchConvey when goconvey integration is not needed
+// - Checks the expectations declared by MockBuilder.Expect when the context ends
 //
 // Usage example:
 //
@@ -133,14 +306,46 @@ func PatchConvey(items ...interface{}) {
 //	// All mocks are cleaned up
 //	resultA := functionA() // Returns original value
 func PatchRun(f func()) {
-	gMocker = append(gMocker, make(map[uintptr]mockerInstance))
-	defer func() {
-		for _, mocker := range gMocker[len(gMocker)-1] {
-			mocker.unPatch()
-		}
-		gMocker = gMocker[:len(gMocker)-1]
-	}()
+	scope := pushScope("")
+	finished := false
+	defer func() { popScopeOrPanic(scope, finished) }()
 	f()
+	finished = true
+}
+
+// PatchT creates a test context bound to t, which is an alternative of `PatchConvey` and `PatchRun` for plain tests.
+// All mocks created after PatchT in the test are unpatched automatically when the test and all its subtests finish,
+// and the expectations declared by MockBuilder.Expect are checked and reported by t.Errorf if the test has not failed.
+// PatchT requires go1.14 or later.
+//
+// Usage example:
+//
+//	func TestFoo(t *testing.T) {
+//	    PatchT(t)
+//	    Mock(functionA).Return("outer").Build()
+//
+//	    t.Run("sub", func(t *testing.T) {
+//	        PatchT(t)
+//	        Mock(functionB).Return("inner").Build()
+//	        // Both functionA and functionB are mocked here
+//	    })
+//	    // Only functionB is cleaned up, functionA remains mocked
+//	}
+//	// All mocks are cleaned up
+//
+// The context is owned by the goroutine of t, so parallel tests using PatchT can mock different targets safely, while
+// mocking the same target in parallel tests fails with ErrAlreadyMocked.
+func PatchT(t testing.TB) {
+	t.Helper()
+	c, ok := t.(interface{ Cleanup(func()) })
+	tool.Assert(ok, "PatchT requires testing.TB with Cleanup, please use go1.14 or later")
+	scope := pushScope(t.Name())
+	c.Cleanup(func() {
+		t.Helper()
+		for _, msg := range popScope(scope, !t.Failed()) {
+			t.Errorf("mockey: unmet expectation: %s", msg)
+		}
+	})
 }
 
 // UnPatchAll unpatch all mocks in current `PatchConvey` or `PatchRun` context. If the caller is out of `PatchConvey`
@@ -166,7 +371,87 @@ func PatchRun(f func()) {
 //		}
 //	}
 func UnPatchAll() {
-	for _, mocker := range gMocker[len(gMocker)-1] {
-		mocker.unPatch()
+	gLock.Lock()
+	mockers := append([]mockerInstance(nil), currentScope().mockers...)
+	gLock.Unlock()
+	for i := len(mockers) - 1; i >= 0; i-- {
+		mockers[i].unPatch()
+	}
+}
+
+// VerifyNoLeaks runs the tests by m and fails the run if any mock is still patched after all tests finish, which is
+// usually built outside `PatchConvey`, `PatchRun` and PatchT and never unpatched. The leaked mocks are listed with the
+// locations where they are patched. VerifyNoLeaks calls os.Exit, so it should be the last call of TestMain.
+//
+// Usage example:
+//
+//	func TestMain(m *testing.M) {
+//	    VerifyNoLeaks(m)
+//	}
+func VerifyNoLeaks(m *testing.M) {
+	code := m.Run()
+	gLock.Lock()
+	leaks := leakedMocks(gScopes)
+	gLock.Unlock()
+	for _, leak := range leaks {
+		fmt.Fprintf(os.Stderr, "mockey: mock leaked: %s, patched at: %v\n", leak.name(), leak.caller())
+	}
+	if len(leaks) > 0 && code == 0 {
+		fmt.Fprintln(os.Stderr, "FAIL")
+		code = 1
+	}
+	os.Exit(code)
+}
+
+// VerifyNoLeaksT fails t if any mock built outside `PatchConvey`, `PatchRun` and PatchT by the goroutine of the test
+// or its descendants, see IncludeCurrentGoRoutineTree, is still patched when the test finishes. The leaked mocks are
+// reported by t.Errorf and then unpatched, so they don't affect the later tests. The mocks patched before
+// VerifyNoLeaksT or by the other tests are ignored. VerifyNoLeaksT requires go1.14 or later.
+//
+// Usage example:
+//
+//	func TestFoo(t *testing.T) {
+//	    VerifyNoLeaksT(t)
+//	    Mock(functionA).Return("leaked").Build() // reported when TestFoo finishes
+//	}
+func VerifyNoLeaksT(t testing.TB) {
+	t.Helper()
+	c, ok := t.(interface{ Cleanup(func()) })
+	tool.Assert(ok, "VerifyNoLeaksT requires testing.TB with Cleanup, please use go1.14 or later")
+	check := &leakCheck{gid: tool.GetGoroutineID()}
+	gLock.Lock()
+	gLeakChecks = append(gLeakChecks, check)
+	gLock.Unlock()
+	c.Cleanup(func() {
+		t.Helper()
+		gLock.Lock()
+		for i, ch := range gLeakChecks {
+			if ch == check {
+				gLeakChecks = append(gLeakChecks[:i:i], gLeakChecks[i+1:]...)
+				break
+			}
+		}
+		var leaks []mockerInstance
+		for _, mocker := range check.mockers {
+			for _, m := range gRootScope.mockers {
+				if m == mocker {
+					leaks = append(leaks, mocker)
+					break
+				}
+			}
+		}
+		gLock.Unlock()
+		for i := len(leaks) - 1; i >= 0; i-- {
+			t.Errorf("mockey: mock leaked: %s, patched at: %v", leaks[i].name(), leaks[i].caller())
+			leaks[i].unPatch()
+		}
+	})
+}
+
+// leakedMocks returns the mocks registered in the scopes, gLock must be held
+func leakedMocks(scopes []*mockScope) (leaks []mockerInstance) {
+	for _, scope := range scopes {
+		leaks = append(leaks, scope.mockers...)
 	}
+	return leaks
 }
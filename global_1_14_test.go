@@ -0,0 +1,175 @@
+//go:build go1.14
+// +build go1.14
+
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPatchT(t *testing.T) {
+	t.Run("nested", func(t *testing.T) {
+		PatchT(t)
+		m1 := Mock(Fun1).Return(true).Build()
+
+		t.Run("sub", func(t *testing.T) {
+			PatchT(t)
+			m2 := Mock(Fun2).Return(true).Build()
+			if r := Fun0(); r != "fun2" {
+				t.Errorf("result = %s, expected = fun2", r)
+			}
+			m1.Verify(t, Once())
+			m2.Verify(t, Once())
+		})
+
+		if r := Fun0(); r != "xxx" {
+			t.Errorf("result = %s, expected = xxx", r)
+		}
+		m1.Verify(t, Exactly(2))
+	})
+	if Fun1() || Fun2() {
+		t.Error("mocks are not cleaned up")
+	}
+
+	t.Run("expect", func(t *testing.T) {
+		tb := &recordTB{TB: t}
+		t.Run("unmet", func(t *testing.T) {
+			tb.TB = t
+			PatchT(tb)
+			Mock(Fun1).Return(true).Expect(Once()).Build()
+		})
+		if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "mockey.Fun1 expected to be called exactly 1 time") {
+			t.Errorf("unexpected errors: %v", tb.errors)
+		}
+	})
+	t.Run("parallel", func(t *testing.T) {
+		t.Run("a", func(t *testing.T) {
+			t.Parallel()
+			PatchT(t)
+			Mock(Fun1).Return(true).Build()
+			if !Fun1() {
+				t.Error("mock of Fun1 is not applied")
+			}
+		})
+		t.Run("b", func(t *testing.T) {
+			t.Parallel()
+			PatchT(t)
+			Mock(Fun2).Return(true).Build()
+			if !Fun2() {
+				t.Error("mock of Fun2 is not applied")
+			}
+		})
+	})
+	if Fun1() || Fun2() {
+		t.Error("mocks of parallel tests are not cleaned up")
+	}
+
+	t.Run("concurrent", func(t *testing.T) {
+		// run the subtests concurrently without t.Parallel, so the mock of a is held while b mocks the same target
+		mocked, clashed := make(chan struct{}), make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			t.Run("a", func(t *testing.T) {
+				PatchT(t)
+				Mock(Fun1).Return(true).Build()
+				close(mocked)
+				<-clashed
+				if !Fun1() {
+					t.Error("mock of Fun1 is broken by a concurrent test")
+				}
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			t.Run("b", func(t *testing.T) {
+				PatchT(t)
+				<-mocked
+				Mock(Fun2).Return(true).Build()
+				_, err := Mock(Fun1).Return(false).TryBuild()
+				close(clashed)
+				if !errors.Is(err, ErrAlreadyMocked) {
+					t.Errorf("err = %v, expected = %v", err, ErrAlreadyMocked)
+				}
+				if !Fun2() {
+					t.Error("mock of Fun2 is not applied")
+				}
+			})
+		}()
+		wg.Wait()
+	})
+	if Fun1() || Fun2() {
+		t.Error("mocks of concurrent tests are not cleaned up")
+	}
+}
+
+func TestVerifyNoLeaksT(t *testing.T) {
+	tb := &recordTB{TB: t}
+	t.Run("leak", func(t *testing.T) {
+		tb.TB = t
+		VerifyNoLeaksT(tb)
+		Mock(Fun3).Return(true).Build()
+		PatchRun(func() { Mock(Fun2).Return(true).Build() })
+	})
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "mockey.Fun3, patched at:") {
+		t.Errorf("unexpected errors: %v", tb.errors)
+	}
+	if Fun3() {
+		t.Error("leaked mock is not unpatched")
+	}
+
+	t.Run("concurrent", func(t *testing.T) {
+		// the mock built by b while a is running is not a leak of a
+		tb := &recordTB{TB: t}
+		started, built, checked := make(chan struct{}), make(chan struct{}), make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			t.Run("a", func(t *testing.T) {
+				tb.TB = t
+				VerifyNoLeaksT(tb)
+				close(started)
+				<-built
+			})
+			close(checked)
+		}()
+		go func() {
+			defer wg.Done()
+			t.Run("b", func(t *testing.T) {
+				<-started
+				mocker := Mock(Fun3).Return(true).Build()
+				close(built)
+				<-checked
+				if !Fun3() {
+					t.Error("mock of a concurrent test is unpatched")
+				}
+				mocker.UnPatch()
+			})
+		}()
+		wg.Wait()
+		if len(tb.errors) != 0 {
+			t.Errorf("unexpected errors: %v", tb.errors)
+		}
+	})
+}
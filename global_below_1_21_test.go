@@ -0,0 +1,63 @@
+//go:build go1.14 && !go1.21
+// +build go1.14,!go1.21
+
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"testing"
+)
+
+func TestPatchTBelow121(t *testing.T) {
+	t.Run("re-mock in subtest", func(t *testing.T) {
+		PatchT(t)
+		Mock(Fun1).Return(true).Build()
+		t.Run("sub", func(t *testing.T) {
+			PatchT(t)
+			if _, err := Mock(Fun1).Return(false).TryBuild(); err != nil {
+				t.Fatalf("re-mock failed: %v", err)
+			}
+			if Fun1() {
+				t.Error("re-mock of Fun1 is not applied")
+			}
+		})
+		if !Fun1() {
+			t.Error("mock of Fun1 is not restored")
+		}
+	})
+	if Fun1() {
+		t.Error("mocks are not cleaned up")
+	}
+}
+
+func TestPatchRunBelow121(t *testing.T) {
+	PatchRun(func() {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			Mock(Fun2).Return(true).Build()
+		}()
+		<-done
+		if !Fun2() {
+			t.Error("mock of Fun2 is not applied")
+		}
+	})
+	if Fun2() {
+		t.Error("mock built in the goroutine of PatchRun is not cleaned up")
+	}
+}
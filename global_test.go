@@ -198,6 +198,32 @@ func TestPatchRun(t *testing.T) {
 	})
 }
 
+// TestPatchRun_Worker tests the mocks built by a goroutine created before PatchRun, like the workers of a pool
+func TestPatchRun_Worker(t *testing.T) {
+	jobs, done := make(chan func()), make(chan struct{})
+	go func() {
+		go func() {
+			for job := range jobs {
+				job()
+				done <- struct{}{}
+			}
+		}()
+	}()
+
+	PatchRun(func() {
+		jobs <- func() { Mock(Fun2).Return(true).Build() }
+		<-done
+		if !Fun2() {
+			t.Error("mock of Fun2 is not applied")
+		}
+	})
+	close(jobs)
+
+	if Fun2() {
+		t.Error("mock built by the worker is not cleaned up")
+	}
+}
+
 // TestUnpatchAll_PatchRun tests UnpatchAll functionality within PatchRun
 func TestUnpatchAll_PatchRun(t *testing.T) {
 	fn1 := func() string {
@@ -262,3 +288,49 @@ func TestUnpatchAll_PatchRun(t *testing.T) {
 		t.Errorf("mock state incorrect after final UnPatchAll: fn1=%q, fn2=%q, fn3=%q", r1, r2, r3)
 	}
 }
+
+func TestExpect(t *testing.T) {
+	PatchConvey("TestExpect", t, func() {
+		PatchConvey("met", func() {
+			convey.So(func() {
+				PatchRun(func() {
+					Mock(Fun1).Return(true).Expect(Once()).Build()
+					Mock(Fun2).Return(true).Expect(Never()).Build()
+					Fun1()
+				})
+			}, convey.ShouldNotPanic)
+		})
+		PatchConvey("unmet", func() {
+			var err interface{}
+			func() {
+				defer func() { err = recover() }()
+				PatchRun(func() {
+					Mock(Fun1).Return(true).Expect(Once()).Build()
+					Mock(Fun2).Return(true).Expect(AtLeast(1)).Build()
+					Mock(Fun3).Return(true).Expect(Once()).Build()
+					Fun1()
+				})
+			}()
+			errString, ok := err.(string)
+			convey.So(ok, convey.ShouldBeTrue)
+			convey.So(errString, convey.ShouldNotContainSubstring, "mockey.Fun1")
+			convey.So(errString, convey.ShouldContainSubstring, "mockey.Fun2 expected to be called at least 1 time, but called 0 times")
+			convey.So(errString, convey.ShouldContainSubstring, "mockey.Fun3 expected to be called exactly 1 time, but called 0 times")
+			// all mocks are unpatched even if the expectations are unmet
+			convey.So(Fun1(), convey.ShouldBeFalse)
+			convey.So(Fun2(), convey.ShouldBeFalse)
+			convey.So(Fun3(), convey.ShouldBeFalse)
+		})
+		PatchConvey("skipped when panicking", func() {
+			var err interface{}
+			func() {
+				defer func() { err = recover() }()
+				PatchRun(func() {
+					Mock(Fun1).Return(true).Expect(Once()).Build()
+					panic("inner panic")
+				})
+			}()
+			convey.So(err, convey.ShouldEqual, "inner panic")
+		})
+	})
+}
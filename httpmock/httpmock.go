@@ -0,0 +1,196 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package httpmock stubs the HTTP requests by patching (*http.Transport).RoundTrip and (*http.Client).Do with
+// mockey.Mock, so the clients which can't be injected, such as http.DefaultClient, can be tested without the network.
+//
+// Example:
+//
+//	mockey.PatchConvey("client", t, func() {
+//		router := httpmock.Mock()
+//		router.Respond("api.example.com", "/v1/users", http.StatusOK, `[]`)
+//		resp, err := http.Get("http://api.example.com/v1/users") // 200 []
+//	})
+//
+// The requests matching no route reach the network as usual, so the third-party APIs can be stubbed while the test
+// still talks to a real server, such as an httptest.Server.
+package httpmock
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/mockey"
+)
+
+// Router routes the stubbed requests to the handlers
+type Router struct {
+	lock     sync.Mutex
+	routes   []*route
+	requests []*http.Request
+	mockers  []*mockey.Mocker
+}
+
+type route struct {
+	host    string // glob pattern of the host, empty matches all hosts
+	path    string // glob pattern of the path, or the prefix if it ends with a slash, empty matches all paths
+	handler http.Handler
+}
+
+// Mock creates a router and patches (*http.Transport).RoundTrip and (*http.Client).Do. The requests matching the
+// routes of the router are served by the handlers locally, and the others fall through to the origin. The goroutine
+// options apply to both patches, see mockey.GoroutineOpt.
+//
+// The requests of a client are stubbed in Client.Do by replacing its transport, so the redirects, cookies and timeouts
+// of the client still work, even if the client has a custom transport.
+func Mock(opts ...mockey.GoroutineOpt) *Router {
+	r := &Router{}
+	mock := func(target, origin, hook interface{}) {
+		builder := mockey.Mock(target).Origin(origin).To(hook).FilterGoRoutineOpts(opts...)
+		r.mockers = append(r.mockers, builder.RecordCalls(0).Build())
+	}
+
+	var roundTrip func(t *http.Transport, req *http.Request) (*http.Response, error)
+	mock((*http.Transport).RoundTrip, &roundTrip, func(t *http.Transport, req *http.Request) (*http.Response, error) {
+		if route := r.match(req); route != nil {
+			return r.serve(route, req)
+		}
+		return roundTrip(t, req)
+	})
+
+	var do func(c *http.Client, req *http.Request) (*http.Response, error)
+	mock((*http.Client).Do, &do, func(c *http.Client, req *http.Request) (*http.Response, error) {
+		if r.match(req) == nil {
+			return do(c, req)
+		}
+		stub := *c
+		stub.Transport = &transport{router: r, base: c.Transport}
+		return do(&stub, req)
+	})
+	return r
+}
+
+// UnPatch removes the stub early, all requests reach the network again
+func (r *Router) UnPatch() {
+	for i := len(r.mockers) - 1; i >= 0; i-- {
+		r.mockers[i].UnPatch()
+	}
+}
+
+// Handle routes the requests matching host and path to handler. Host is a glob pattern of path.Match, such as
+// "*.example.com", and includes the port if the URL has one. Path is a glob pattern too, or a prefix if it ends with a
+// slash like http.ServeMux. The empty pattern matches all. The routes are matched in the order they are added.
+func (r *Router) Handle(host, path string, handler http.Handler) *Router {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.routes = append(r.routes, &route{host: host, path: path, handler: handler})
+	return r
+}
+
+// HandleFunc routes the requests matching host and path to handler, see Handle
+func (r *Router) HandleFunc(host, path string, handler func(http.ResponseWriter, *http.Request)) *Router {
+	return r.Handle(host, path, http.HandlerFunc(handler))
+}
+
+// Respond routes the requests matching host and path to a canned response with status and body, see Handle
+func (r *Router) Respond(host, path string, status int, body string) *Router {
+	return r.HandleFunc(host, path, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	})
+}
+
+// Requests returns the stubbed requests in the order they are served. Their bodies have been read by the handlers.
+func (r *Router) Requests() []*http.Request {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]*http.Request(nil), r.requests...)
+}
+
+func (r *Router) match(req *http.Request) *route {
+	if req == nil || req.URL == nil {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, route := range r.routes {
+		if route.match(req.URL.Host, req.URL.Path) {
+			return route
+		}
+	}
+	return nil
+}
+
+func (rt *route) match(host, urlPath string) bool {
+	if rt.host != "" {
+		if ok, _ := path.Match(rt.host, host); !ok {
+			return false
+		}
+	}
+	switch {
+	case rt.path == "":
+		return true
+	case strings.HasSuffix(rt.path, "/"):
+		return strings.HasPrefix(urlPath, rt.path)
+	default:
+		ok, _ := path.Match(rt.path, urlPath)
+		return ok
+	}
+}
+
+// serve serves the request by the handler of the route like a server, and records the request
+func (r *Router) serve(rt *route, req *http.Request) (*http.Response, error) {
+	r.lock.Lock()
+	r.requests = append(r.requests, req)
+	r.lock.Unlock()
+
+	serverReq := req.Clone(req.Context())
+	serverReq.RequestURI = req.URL.RequestURI()
+	if serverReq.Host == "" {
+		serverReq.Host = req.URL.Host
+	}
+	if serverReq.Body == nil {
+		serverReq.Body = http.NoBody
+	}
+	recorder := httptest.NewRecorder()
+	rt.handler.ServeHTTP(recorder, serverReq)
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	resp := recorder.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// transport serves the matched requests by the router, and sends the others by the base transport
+type transport struct {
+	router *Router
+	base   http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if route := t.router.match(req); route != nil {
+		return t.router.serve(route, req)
+	}
+	if t.base == nil {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpmock
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/bytedance/mockey"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func get(c *http.Client, url string) (int, string) {
+	resp, err := c.Get(url)
+	So(err, ShouldBeNil)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	So(err, ShouldBeNil)
+	return resp.StatusCode, string(body)
+}
+
+func TestRouter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "real")
+	}))
+	defer server.Close()
+	serverHost := strings.TrimPrefix(server.URL, "http://")
+
+	mockey.PatchConvey("TestRouter", t, func() {
+		mockey.PatchConvey("default client", func() {
+			router := Mock()
+			router.Respond("api.example.com", "/v1/users", http.StatusCreated, "[]")
+			status, body := get(http.DefaultClient, "http://api.example.com/v1/users")
+			So(status, ShouldEqual, http.StatusCreated)
+			So(body, ShouldEqual, "[]")
+			So(len(router.Requests()), ShouldEqual, 1)
+			So(router.Requests()[0].URL.Path, ShouldEqual, "/v1/users")
+
+			status, body = get(http.DefaultClient, server.URL)
+			So(status, ShouldEqual, http.StatusOK)
+			So(body, ShouldEqual, "real")
+			So(len(router.Requests()), ShouldEqual, 1)
+		})
+		mockey.PatchConvey("handler", func() {
+			router := Mock()
+			router.HandleFunc("*.example.com", "/echo/", func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				_, _ = io.WriteString(w, r.Host+r.URL.Path+":"+string(body))
+			})
+			resp, err := http.Post("http://a.example.com/echo/x", "text/plain", strings.NewReader("hello"))
+			So(err, ShouldBeNil)
+			body, _ := ioutil.ReadAll(resp.Body)
+			So(string(body), ShouldEqual, "a.example.com/echo/x:hello")
+		})
+		mockey.PatchConvey("custom transport", func() {
+			Mock().Respond("api.example.com", "", http.StatusOK, "stubbed")
+			client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return nil, errors.New("unreachable")
+			})}
+			_, body := get(client, "http://api.example.com/anything")
+			So(body, ShouldEqual, "stubbed")
+		})
+		mockey.PatchConvey("redirect", func() {
+			router := Mock()
+			router.HandleFunc("api.example.com", "/old", func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/new", http.StatusFound)
+			})
+			router.Respond("api.example.com", "/new", http.StatusOK, "new")
+			_, body := get(&http.Client{}, "http://api.example.com/old")
+			So(body, ShouldEqual, "new")
+			So(len(router.Requests()), ShouldEqual, 2)
+		})
+		mockey.PatchConvey("transport", func() {
+			Mock().Respond(serverHost, "", http.StatusOK, "stubbed")
+			req := &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "http", Host: serverHost, Path: "/"}, Header: http.Header{}}
+			resp, err := http.DefaultTransport.RoundTrip(req)
+			So(err, ShouldBeNil)
+			body, _ := ioutil.ReadAll(resp.Body)
+			So(string(body), ShouldEqual, "stubbed")
+		})
+		mockey.PatchConvey("goroutine filter", func() {
+			Mock(mockey.OptExcludeCurrentGoRoutine()).Respond(serverHost, "", http.StatusOK, "stubbed")
+			_, body := get(http.DefaultClient, server.URL)
+			So(body, ShouldEqual, "real")
+			done := make(chan string)
+			go func() {
+				resp, err := http.Get(server.URL)
+				if err != nil {
+					done <- err.Error()
+					return
+				}
+				body, _ := ioutil.ReadAll(resp.Body)
+				done <- string(body)
+			}()
+			So(<-done, ShouldEqual, "stubbed")
+		})
+	})
+}
@@ -18,9 +18,14 @@ package inst
 
 import "unsafe"
 
+// BranchTo create a branch to command without touching any register
+//
+// The proxy jumps back into the middle of the target function, the copied instructions before the cutting point may
+// have already written a register (for example: RDX in the prologue-less generic shape function). So the destination
+// is stored right after the instruction instead of being loaded into RDX.
 func BranchTo(to uintptr) (res []byte) {
-	res = append(res, rdxMOV(to)...)         // MOVABS RDX, to
-	res = append(res, []byte{0xff, 0xe2}...) // JMP RDX
+	res = append(res, []byte{0xff, 0x25, 0x00, 0x00, 0x00, 0x00}...) // JMP [RIP+0]
+	res = append(res, uintptrBytes(to)...)                           // to
 	return
 }
 
@@ -33,8 +38,12 @@ func BranchInto(to uintptr) (res []byte) {
 // rdxMOV moves the 64bit value to rdx register, using the following instruction:
 // MOVABS RDX, val
 func rdxMOV(val uintptr) []byte {
+	return append([]byte{0x48, 0xba}, uintptrBytes(val)...)
+}
+
+// uintptrBytes returns the little-endian bytes of the 64bit value
+func uintptrBytes(val uintptr) []byte {
 	res := make([]byte, unsafe.Sizeof(val))
 	*(*uintptr)(unsafe.Pointer(&res[0])) = val
-	res = append([]byte{0x48, 0xba}, res...)
 	return res
 }
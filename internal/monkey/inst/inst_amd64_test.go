@@ -29,3 +29,10 @@ func Test_rdxMOV(t *testing.T) {
 		convey.So(inst, convey.ShouldEqual, "48ba01efbc9a78563412")
 	})
 }
+
+func TestBranchTo(t *testing.T) {
+	convey.Convey("TestBranchTo", t, func() {
+		inst := fmt.Sprintf("%x", BranchTo(0x123456789abcef01))
+		convey.So(inst, convey.ShouldEqual, "ff250000000001efbc9a78563412")
+	})
+}
@@ -44,6 +44,33 @@ func (p *Patch) Unpatch() {
 	common.ReleasePage(p.code)
 }
 
+// Redirect makes the patched function jump into another hook function, the original codes are still restored by
+// Unpatch.
+func (p *Patch) Redirect(hook reflect.Value) {
+	mem.WriteWithSTW(p.base, inst.BranchInto(common.PtrAt(hook)))
+}
+
+// Link makes the proxy function jump into the target function. Proxy is a value of proxy function pointer. The codes
+// of the proxy function are returned, which can be changed by Relink and should be released by Unlink.
+func Link(proxy, target reflect.Value) []byte {
+	tool.Assert(target.Kind() == reflect.Func, "'%s' is not a function", target.Kind())
+	tool.Assert(proxy.Kind() == reflect.Ptr, "'%v' is not a function pointer", proxy.Kind())
+	proxyCode := common.AllocatePage()
+	copy(proxyCode, inst.BranchInto(common.PtrAt(target)))
+	fn.InjectInto(proxy, proxyCode)
+	return proxyCode
+}
+
+// Relink makes the proxy function linked by Link jump into another target function.
+func Relink(proxyCode []byte, target reflect.Value) {
+	mem.WriteWithSTW(common.PtrOf(proxyCode), inst.BranchInto(common.PtrAt(target)))
+}
+
+// Unlink releases the codes of the proxy function linked by Link.
+func Unlink(proxyCode []byte) {
+	common.ReleasePage(proxyCode)
+}
+
 // PatchValue replace the target function with a hook function, and stores the target function in the proxy function
 // for future restore. Target and hook are values of function. Proxy is a value of proxy function pointer.
 func PatchValue(target, hook, proxy reflect.Value, unsafe bool) *Patch {
@@ -78,3 +105,10 @@ func PatchFunc(fn, hook, proxy interface{}, unsafe bool) *Patch {
 	tool.Assert(vv.Kind() == reflect.Func, "'%v' is not a function", fn)
 	return PatchValue(vv, reflect.ValueOf(hook), reflect.ValueOf(proxy), unsafe)
 }
+
+// CheckValue checks whether the target function can be patched by PatchValue without patching it.
+func CheckValue(target reflect.Value, unsafe bool) {
+	const bufSize = 64
+	targetCodeBuf := common.BytesOf(target.Pointer(), bufSize)
+	inst.Disassemble(targetCodeBuf, len(inst.BranchInto(0)), !unsafe)
+}
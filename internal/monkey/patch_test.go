@@ -72,5 +72,19 @@ func TestPatchFunc(t *testing.T) {
 			patch.Unpatch()
 			So(Target("anything"), ShouldEqual, "anything")
 		})
+		Convey("layered hook", func() {
+			var proxy, below func(string) string
+			patch := PatchFunc(Target, Hook, &proxy, false)
+			proxyCode := Link(reflect.ValueOf(&below), reflect.ValueOf(Hook))
+			patch.Redirect(reflect.ValueOf(func(in string) string { return "LAYERED " + below(in) }))
+			So(Target("anything"), ShouldEqual, "LAYERED MOCKED!")
+			Relink(proxyCode, reflect.ValueOf(proxy))
+			So(Target("anything"), ShouldEqual, "LAYERED anything")
+			patch.Redirect(reflect.ValueOf(Hook))
+			Unlink(proxyCode)
+			So(Target("anything"), ShouldEqual, "MOCKED!")
+			patch.Unpatch()
+			So(Target("anything"), ShouldEqual, "anything")
+		})
 	})
 }
@@ -66,6 +66,17 @@ func Caller() CallerInfo {
 	return CallerInfo(frame)
 }
 
+// ParentCaller gets the caller of the function that calls ParentCaller.
+// For a hook function created by reflect.MakeFunc, it represents the line where the patched target is called.
+func ParentCaller() CallerInfo {
+	caller, _, _, ok := runtime.Caller(2)
+	if !ok {
+		return CallerInfo(runtime.Frame{File: "Nan"})
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{caller}).Next()
+	return CallerInfo(frame)
+}
+
 func getPackageAndFunction(pc uintptr) (string, string) {
 	parts := strings.Split(runtime.FuncForPC(pc).Name(), ".")
 	pl := len(parts)
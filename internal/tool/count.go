@@ -0,0 +1,36 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"fmt"
+)
+
+// TimesString describes the count n, such as "1 time" and "2 times"
+func TimesString(n int) string {
+	if n == 1 {
+		return "1 time"
+	}
+	return fmt.Sprintf("%d times", n)
+}
+
+// CountFailure describes the failed check of the times a mocker is called or mocked, which is shared by mockey and
+// exp/iface so that they word the failure the same
+func CountFailure(name, action string, expected fmt.Stringer, times, called, mocked int, caller CallerInfo) string {
+	return fmt.Sprintf("%v expected to be %s %v, but %s %s (called %s, mocked %s), mock at: %v",
+		name, action, expected, action, TimesString(times), TimesString(called), TimesString(mocked), caller)
+}
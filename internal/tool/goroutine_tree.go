@@ -0,0 +1,55 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"sync"
+)
+
+// gParents records the parent goroutine IDs tracked explicitly, see TrackGoroutine
+var gParents sync.Map // map[int64]int64
+
+// TrackGoroutine records parent as the parent goroutine of child. It's needed for the goroutines whose parent can't
+// be read from the runtime, see getParentGoroutineID. The records are never removed, so that the descendants can still
+// be found after their ancestors exit, which is safe since goroutine IDs are not reused.
+func TrackGoroutine(child, parent int64) {
+	gParents.Store(child, parent)
+}
+
+// IsGoroutineDescendant reports whether the goroutine gid is root or created by root directly or indirectly. The
+// parent of the current goroutine is read from the runtime and tracked, the other ancestors are searched in the tracked
+// records, so an ancestor which is neither tracked nor has looked up itself breaks the search.
+func IsGoroutineDescendant(gid, root int64) bool {
+	if cur := GetGoroutineID(); gid == cur {
+		if parent := getParentGoroutineID(); parent != 0 {
+			TrackGoroutine(cur, parent)
+		}
+	}
+	visited := map[int64]bool{}
+	for !visited[gid] {
+		if gid == root {
+			return true
+		}
+		visited[gid] = true
+		parent, ok := gParents.Load(gid)
+		if !ok {
+			return false
+		}
+		gid = parent.(int64)
+	}
+	return false
+}
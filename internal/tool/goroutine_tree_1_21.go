@@ -0,0 +1,33 @@
+//go:build go1.21
+// +build go1.21
+
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"unsafe"
+)
+
+// gParentGoroutineIDOffset Go1.21 introduced the `parentGoid` field, and the fields between goid and it take 120 bytes
+// since then
+const gParentGoroutineIDOffset = gGoroutineIDOffset + 120
+
+// getParentGoroutineID returns the ID of the goroutine which created the current goroutine
+func getParentGoroutineID() int64 {
+	return *(*int64)(unsafe.Pointer(uintptr(getG()) + gParentGoroutineIDOffset))
+}
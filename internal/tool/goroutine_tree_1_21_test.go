@@ -0,0 +1,61 @@
+//go:build go1.21
+// +build go1.21
+
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetParentGoroutineID(t *testing.T) {
+	convey.Convey("TestGetParentGoroutineID", t, func() {
+		parent := GetGoroutineID()
+		res := make(chan int64)
+		go func() { res <- getParentGoroutineID() }()
+		convey.So(<-res, convey.ShouldEqual, parent)
+	})
+}
+
+func TestIsGoroutineDescendant(t *testing.T) {
+	convey.Convey("TestIsGoroutineDescendant", t, func() {
+		root := GetGoroutineID()
+		convey.So(IsGoroutineDescendant(root, root), convey.ShouldBeTrue)
+
+		res := make(chan bool)
+		release := make(chan struct{})
+		go func() {
+			child := GetGoroutineID()
+			// the child tracks its parent when it looks up itself
+			res <- IsGoroutineDescendant(child, root)
+			go func() {
+				res <- IsGoroutineDescendant(GetGoroutineID(), root)
+				res <- IsGoroutineDescendant(GetGoroutineID(), child)
+				<-release
+			}()
+			<-release
+		}()
+		convey.So(<-res, convey.ShouldBeTrue)
+		convey.So(<-res, convey.ShouldBeTrue)
+		convey.So(<-res, convey.ShouldBeTrue)
+		convey.So(IsGoroutineDescendant(root, root+(1<<40)), convey.ShouldBeFalse)
+		close(release)
+	})
+}
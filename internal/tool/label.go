@@ -0,0 +1,54 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"context"
+	"reflect"
+	"runtime/pprof"
+	"sync"
+	"unsafe"
+)
+
+var (
+	labelOnce sync.Once
+	labelKey  interface{}  // key of the pprof labels in context
+	labelType reflect.Type // pointer type of the pprof labels in context
+)
+
+// GetGoroutineLabel returns the value of the pprof label key of the current goroutine, which is set by pprof.Do or
+// pprof.SetGoroutineLabels. The labels of the goroutine are put into a context in the same way as pprof.WithLabels,
+// so that they can be read by pprof.Label without knowing their layout.
+func GetGoroutineLabel(key string) (string, bool) {
+	labelOnce.Do(func() {
+		// pprof.WithLabels returns a valueCtx whose key and val are the ones we need
+		ctx := reflect.ValueOf(pprof.WithLabels(context.Background(), pprof.Labels("k", "v"))).Elem()
+		keyField, valField := ctx.FieldByName("key"), ctx.FieldByName("val")
+		Assert(keyField.IsValid() && valField.IsValid(), "unsupported pprof labels context: %v", ctx.Type())
+		labelKey = *(*interface{})(unsafe.Pointer(keyField.UnsafeAddr()))
+		labelType = reflect.TypeOf(*(*interface{})(unsafe.Pointer(valField.UnsafeAddr())))
+	})
+	labels := getProfLabel()
+	if labels == nil {
+		return "", false
+	}
+	ctx := context.WithValue(context.Background(), labelKey, reflect.NewAt(labelType.Elem(), labels).Interface())
+	return pprof.Label(ctx, key)
+}
+
+//go:linkname getProfLabel runtime/pprof.runtime_getProfLabel
+func getProfLabel() unsafe.Pointer
@@ -0,0 +1,31 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"time"
+	_ "unsafe"
+)
+
+// Now returns the current wall time without calling time.Now, so that it is still reliable while time.Now is mocked.
+func Now() time.Time {
+	sec, nsec, _ := now()
+	return time.Unix(sec, int64(nsec))
+}
+
+//go:linkname now time.now
+func now() (sec int64, nsec int32, mono int64)
@@ -17,9 +17,12 @@
 package mockey
 
 import (
+	"fmt"
 	"reflect"
+	"runtime"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/bytedance/mockey/internal/fn"
 	"github.com/bytedance/mockey/internal/monkey"
@@ -32,6 +35,8 @@ const (
 	Disable FilterGoroutineType = 0
 	Include FilterGoroutineType = 1
 	Exclude FilterGoroutineType = 2
+	// IncludeTree includes the goroutine and its descendants, see IncludeCurrentGoRoutineTree
+	IncludeTree FilterGoroutineType = 3
 )
 
 type Mocker struct {
@@ -40,38 +45,58 @@ type Mocker struct {
 	proxy     reflect.Value // proxy pointer value
 	times     int64
 	mockTimes int64
-	patch     *monkey.Patch
+	condTimes []int64     // times each condition is matched
+	missTimes int64       // times no condition is matched and the origin is executed
+	paused    int32       // non-zero means the hook executes the origin directly, see Pause
+	layers    *mockLayers // layers of the patched target, see pushLayer
+	proxyCode []byte      // codes of the proxy linked to the layer below, nil if the proxy is the origin
 	lock      sync.Mutex
 	isPatched bool
 	builder   *MockBuilder
 
+	calls     []CallRecord // invocation records
+	callsLock sync.Mutex
+
 	outerCaller tool.CallerInfo
 }
 
 type MockBuilder struct {
-	target          interface{}      // mock target
-	originPtr       interface{}      // origin caller
-	conditions      []*mockCondition // mock conditions
-	filterGoroutine FilterGoroutineType
-	gId             int64
+	target          interface{}          // mock target
+	originPtr       interface{}          // origin caller
+	conditions      []*mockCondition     // mock conditions
+	filterGoroutine func(gId int64) bool // whether the mock takes effect in the goroutine, nil means all goroutines
+	filterMode      string               // mode of the goroutine filter, see MockInfo.GoroutineFilter
 	unsafe          bool
 	analyzer        fn.Analyzer
+	expect          CountOpt                                   // expected call count, checked when the PatchConvey or PatchRun scope ends
+	forContext      bool                                       // only take effect in the context carrying the mocker, see ForContext
+	spy             bool                                       // call through to the origin and record the matched calls only, see Spy
+	callLimit       int                                        // max number of the call records, see RecordCalls
+	delay           func() time.Duration                       // delay before each call, see Delay and Jitter
+	originExec      func(args []reflect.Value) []reflect.Value // executes the origin of the built mocker
+	err             error                                      // the first failure of the builder, reported when building
 }
 
 // Mock mocks target function.
 // From go1.20, Mock can automatically judge whether the target is generic or not. Before go1.20, you need to use
 // MockGeneric to mock generic function.
+//
+// Failures of the builder, such as a hook whose signature does not match the target, are recorded and reported when
+// building, see Build, TryBuild and Validate.
 func Mock(target interface{}, opt ...mockOptionFn) *MockBuilder {
-	tool.AssertFunc(target)
-
 	opts := resolveMockOpt(opt...)
 
 	builder := &MockBuilder{
-		target:   target,
-		unsafe:   opts.unsafe,
-		analyzer: fn.NewAnalyzer(target, opts.generic, opts.method),
+		target:    target,
+		unsafe:    opts.unsafe,
+		callLimit: defaultCallLimit,
 	}
 	builder.resetCondition()
+	if typ := reflect.TypeOf(target); typ == nil || typ.Kind() != reflect.Func {
+		builder.fail(newMockError(ErrNotFunction, "'%v' is not a function", target))
+		return builder
+	}
+	builder.fail(catch(nil, func() { builder.analyzer = fn.NewAnalyzer(target, opts.generic, opts.method) }))
 	return builder
 }
 
@@ -101,18 +126,57 @@ func (builder *MockBuilder) resetCondition() *MockBuilder {
 //	 }
 //	 mock2 := Mock(Fun).To(mock).Origin(&origin).Build()
 //
-// Origin only works when call origin hook directly, target will still be mocked in recursive call
+// Origin only works when call origin hook directly, target will still be mocked in recursive call. If the target has
+// already been mocked, origin calls the previous mock, see pushLayer.
 func (builder *MockBuilder) Origin(funcPtr interface{}) *MockBuilder {
-	tool.Assert(builder.originPtr == nil, "re-set builder origin")
-	return builder.origin(funcPtr)
+	return builder.do(func() error {
+		if builder.originPtr != nil {
+			return newMockError(ErrInvalidUsage, "re-set builder origin")
+		}
+		return builder.origin(funcPtr)
+	})
 }
 
-func (builder *MockBuilder) origin(funcPtr interface{}) *MockBuilder {
-	tool.AssertPtr(funcPtr)
+func (builder *MockBuilder) origin(funcPtr interface{}) error {
+	typ := reflect.TypeOf(funcPtr)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Func {
+		return newMockError(ErrNotFunction, "'%v' is not a function pointer", funcPtr)
+	}
+	if err := catch(ErrSignatureMismatch, func() {
+		tool.CheckFuncReturnValues(builder.analyzer.TargetType(), typ.Elem())
+		builder.analyzer.ReversedInputAdapter("origin", typ.Elem())
+	}); err != nil {
+		return err
+	}
 	builder.originPtr = funcPtr
+	return nil
+}
+
+// do runs f and records its failure, f is skipped if the builder has already failed
+func (builder *MockBuilder) do(f func() error) *MockBuilder {
+	if builder.err == nil {
+		builder.fail(f())
+	}
 	return builder
 }
 
+// fail records the first failure of the builder
+func (builder *MockBuilder) fail(err error) {
+	if err != nil && builder.err == nil {
+		builder.err = builder.stampError(err)
+	}
+}
+
+func (builder *MockBuilder) name() string {
+	if typ := reflect.TypeOf(builder.target); typ == nil || typ.Kind() != reflect.Func {
+		return fmt.Sprintf("%v", builder.target)
+	}
+	if f := runtime.FuncForPC(reflect.ValueOf(builder.target).Pointer()); f != nil {
+		return f.Name()
+	}
+	return reflect.ValueOf(builder.target).String()
+}
+
 func (builder *MockBuilder) lastCondition() *mockCondition {
 	cond := builder.conditions[len(builder.conditions)-1]
 	if cond.Complete() {
@@ -148,8 +212,30 @@ func (builder *MockBuilder) newCondition() *mockCondition {
 //	}
 //	Mock((*Foo).GetAge).When(func(f *Foo, younger int) bool { return younger < 0 }).Return("0").Build()
 func (builder *MockBuilder) When(when interface{}) *MockBuilder {
-	builder.lastCondition().SetWhen(when)
-	return builder
+	return builder.do(func() error { return builder.lastCondition().SetWhen(when) })
+}
+
+// Named names the condition being declared, or the last declared one, so that the times it is matched can be queried
+// by Mocker.ConditionTimes.
+//
+// For example:
+//
+//	mocker := Mock(Fun).When(func(input int) bool { return input < 0 }).Named("negative").Return("0").Build()
+//	Fun(-1)
+//	mocker.ConditionTimes("negative") // 1
+func (builder *MockBuilder) Named(name string) *MockBuilder {
+	return builder.do(func() error {
+		if name == "" {
+			return newMockError(ErrInvalidUsage, "condition name should not be empty")
+		}
+		for _, cond := range builder.conditions {
+			if cond.name == name {
+				return newMockError(ErrInvalidUsage, "duplicated condition name: %s", name)
+			}
+		}
+		builder.conditions[len(builder.conditions)-1].name = name
+		return nil
+	})
 }
 
 // To declares the hook function that's called to replace the target function.
@@ -175,34 +261,141 @@ func (builder *MockBuilder) When(when interface{}) *MockBuilder {
 //	}
 //	Mock((*Foo).Bar).To(func(f *Foo, other string) bool {return true}).Build()
 func (builder *MockBuilder) To(hook interface{}) *MockBuilder {
-	builder.lastCondition().SetTo(hook)
-	return builder
+	return builder.do(func() error { return builder.lastCondition().SetTo(hook) })
 }
 
 func (builder *MockBuilder) Return(results ...interface{}) *MockBuilder {
-	builder.lastCondition().SetReturn(results...)
+	return builder.do(func() error { return builder.lastCondition().SetReturn(results...) })
+}
+
+// Panic declares that the target panics with value when it's called.
+//
+// The following example would make Fun panic when input is empty
+//
+//	Mock(Fun).When(func(input string) bool { return input == "" }).Panic("empty input").Build()
+func (builder *MockBuilder) Panic(value interface{}) *MockBuilder {
+	return builder.do(func() error { return builder.lastCondition().SetReturn(panicResult{value: value}) })
+}
+
+// ReturnErr declares that the target returns err in its last result of type error, and zero values in the others.
+//
+// The following example would make Fun return (0, io.EOF)
+//
+//	func Fun(input string) (int, error) {
+//		return strconv.Atoi(input)
+//	}
+//	Mock(Fun).ReturnErr(io.EOF).Build()
+//
+// To return an error only in some calls, use Sequence with ThenErr, e.g. Return(Sequence(1, nil).ThenErr(io.EOF)).
+func (builder *MockBuilder) ReturnErr(err error) *MockBuilder {
+	return builder.do(func() error { return builder.lastCondition().SetReturn(errResult{err: err}) })
+}
+
+// Expect declares how many times the target must be called. The expectation is checked automatically when the
+// `PatchConvey` or `PatchRun` scope in which the mocker is created ends, and an unmet expectation fails the scope.
+//
+// For example:
+//
+//	PatchRun(func() {
+//		Mock(Fun).Return("mocked").Expect(Once()).Build()
+//		Fun("a")
+//	}) // would fail if Fun is not called exactly once
+//
+// Mocks created out of any scope are never checked automatically, use Mocker.Verify instead.
+func (builder *MockBuilder) Expect(opt CountOpt) *MockBuilder {
+	builder.expect = opt
 	return builder
 }
 
+// Spy makes the mocker record the calls without replacing the target, every call is forwarded to the origin function.
+// Arguments, results and panics of the calls are recorded, see Mocker.Calls. If conditions are declared by When, only
+// the matched calls are recorded. Spy can't be used with Return or To.
+//
+// For example:
+//
+//	spy := Mock(Fun).When(func(a string) bool { return a == "a" }).Spy().Build()
+//	Fun("a") // calls the origin Fun and is recorded
+//	Fun("b") // calls the origin Fun and is not recorded
+func (builder *MockBuilder) Spy() *MockBuilder {
+	return builder.do(func() error {
+		for _, cond := range builder.conditions {
+			if cond.hook != nil {
+				return newMockError(ErrInvalidUsage, "spy with mock hook")
+			}
+		}
+		builder.spy = true
+		return nil
+	})
+}
+
 func (builder *MockBuilder) IncludeCurrentGoRoutine() *MockBuilder {
 	return builder.FilterGoRoutine(Include, tool.GetGoroutineID())
 }
 
+// IncludeCurrentGoRoutineTree makes the mock only take effect in the current goroutine and the goroutines created by it
+// directly or indirectly. Since go1.21, the parent of a goroutine calling the target is read from the runtime, so the
+// children are always found, and the deeper descendants are found if the goroutines in between have called the target
+// before or are started by Go. Before go1.21, the descendants must be started by Go.
+func (builder *MockBuilder) IncludeCurrentGoRoutineTree() *MockBuilder {
+	return builder.FilterGoRoutine(IncludeTree, tool.GetGoroutineID())
+}
+
 func (builder *MockBuilder) ExcludeCurrentGoRoutine() *MockBuilder {
 	return builder.FilterGoRoutine(Exclude, tool.GetGoroutineID())
 }
 
 func (builder *MockBuilder) FilterGoRoutine(filter FilterGoroutineType, gId int64) *MockBuilder {
-	builder.filterGoroutine = filter
-	builder.gId = gId
-	return builder
+	return builder.FilterGoRoutines(filter, gId)
 }
 
+// Build builds and patches the mocker. The failure of the builder is reported by the failure handler, which panics by
+// default, see SetFailureHandler.
 func (builder *MockBuilder) Build() *Mocker {
-	mocker := Mocker{builder: builder}
-	mocker.build()
-	mocker.Patch()
-	return &mocker
+	mocker, err := builder.TryBuild()
+	if err != nil {
+		handleFailure(err)
+		return &Mocker{builder: builder}
+	}
+	return mocker
+}
+
+// TryBuild is like Build, but it returns the failure as a *MockError instead of reporting it.
+//
+// For example:
+//
+//	mocker, err := Mock(Fun).Return(1).TryBuild()
+//	if errors.Is(err, ErrSignatureMismatch) {
+//		// handle the failure
+//	}
+func (builder *MockBuilder) TryBuild() (*Mocker, error) {
+	if err := builder.Validate(); err != nil {
+		return nil, err
+	}
+	mocker := &Mocker{builder: builder}
+	if err := catch(ErrSignatureMismatch, mocker.build); err != nil {
+		return nil, builder.stampError(err)
+	}
+	if err := mocker.patch(); err != nil {
+		return nil, err
+	}
+	return mocker, nil
+}
+
+// Validate checks whether the builder can be built without patching the target. It returns the first failure of the
+// builder, or the failure of building, such as ErrTargetTooShort and ErrAlreadyMocked.
+func (builder *MockBuilder) Validate() error {
+	if builder.err != nil {
+		return builder.err
+	}
+	if err := catch(ErrTargetTooShort, func() {
+		monkey.CheckValue(builder.analyzer.RuntimeTargetValue(), builder.unsafe)
+	}); err != nil {
+		return builder.stampError(err)
+	}
+	if err := checkGlobal(reflect.ValueOf(builder.target).Pointer()); err != nil {
+		return builder.stampError(err)
+	}
+	return nil
 }
 
 func (mocker *Mocker) build() {
@@ -215,11 +408,15 @@ func (mocker *Mocker) build() {
 		exec            []func(args []reflect.Value) []reflect.Value
 	)
 
-	mocker.proxy = reflect.New(mocker.builder.runtimeTargetType())
+	if !mocker.proxy.IsValid() {
+		// the proxy is kept when rebuilding, since the patch or the layer above may jump into it
+		mocker.proxy = reflect.New(mocker.builder.runtimeTargetType())
+	}
 
 	originExec = func(args []reflect.Value) []reflect.Value {
 		return tool.ReflectCall(mocker.proxy.Elem(), args)
 	}
+	mocker.builder.originExec = originExec
 
 	if originPtr := mocker.builder.originPtr; originPtr != nil {
 		origin := reflect.ValueOf(originPtr).Elem()
@@ -250,9 +447,66 @@ func (mocker *Mocker) build() {
 				return tool.ReflectCall(reflect.ValueOf(condition.hook), args)
 			})
 		}
+
+		if effects := condition.effects; len(effects) > 0 {
+			execFn := exec[len(exec)-1]
+			exec[len(exec)-1] = func(args []reflect.Value) []reflect.Value {
+				results := execFn(args)
+				for _, effect := range effects {
+					effect(args)
+				}
+				return results
+			}
+		}
+	}
+
+	mocker.condTimes = make([]int64, len(mocker.builder.conditions))
+	recordAdapter := mocker.builder.analyzer.InputAdapter("record", mocker.builder.analyzer.TargetType())
+
+	// inputsOf returns the arguments of the call in the form of the record
+	inputsOf := func(call *CallRecord, args []reflect.Value) []interface{} {
+		if call == nil {
+			return valuesToInterfaces(recordAdapter(args))
+		}
+		return call.Args
 	}
 
-	mockerHook := reflect.MakeFunc(mocker.builder.runtimeTargetType(), func(args []reflect.Value) []reflect.Value {
+	// dispatch executes the first matched condition and stores its index in the call, -1 means the origin is executed.
+	// The call is nil if it's not recorded.
+	dispatch := func(call *CallRecord, args []reflect.Value) []reflect.Value {
+		if filter := mocker.builder.filterGoroutine; filter != nil && !filter(tool.GetGoroutineID()) {
+			return originExec(args)
+		}
+		if mocker.builder.forContext && !mocker.inContext(inputsOf(call, args)) {
+			return originExec(args)
+		}
+
+		if mocker.builder.delay != nil {
+			if results, aborted := mocker.builder.wait(inputsOf(call, args)); aborted {
+				return results
+			}
+		}
+
+		for i, matchFn := range match {
+			execFn := exec[i]
+			if matchFn(args) {
+				if call != nil {
+					call.Condition = i
+				}
+				atomic.AddInt64(&mocker.condTimes[i], 1)
+				return execFn(args)
+			}
+		}
+
+		atomic.AddInt64(&mocker.missTimes, 1)
+		return originExec(args)
+	}
+
+	mockerHook := reflect.MakeFunc(mocker.builder.runtimeTargetType(), func(args []reflect.Value) (results []reflect.Value) {
+		if atomic.LoadInt32(&mocker.paused) != 0 {
+			return originExec(args)
+		}
+
 		if mocker.builder.originPtr != nil {
 			// Origin call need extra args, which only can be obtained during the execution of mockerHook.
 			extraArgsGetter = func() []reflect.Value { return args }
@@ -270,44 +524,52 @@ func (mocker *Mocker) build() {
 		}
 
 		mocker.access()
-		switch mocker.builder.filterGoroutine {
-		case Disable:
-			break
-		case Include:
-			if tool.GetGoroutineID() != mocker.builder.gId {
-				return originExec(args)
-			}
-		case Exclude:
-			if tool.GetGoroutineID() == mocker.builder.gId {
-				return originExec(args)
-			}
+		if !mocker.recording() {
+			// the call won't be stored, so it's dispatched without being captured
+			return dispatch(nil, args)
 		}
-
-		for i, matchFn := range match {
-			execFn := exec[i]
-			if matchFn(args) {
-				return execFn(args)
+		call := mocker.newCall(inputsOf(nil, args), tool.ParentCaller())
+		defer func() {
+			if p := recover(); p != nil {
+				call.Panic = p
+				mocker.record(call, nil)
+				panic(p)
 			}
-		}
-
-		return originExec(args)
+		}()
+		results = dispatch(call, args)
+		mocker.record(call, results)
+		return results
 	})
 	mocker.hook = mockerHook
 }
 
+// Patch patches the target. The failure is reported by the failure handler, see SetFailureHandler.
 func (mocker *Mocker) Patch() *Mocker {
+	handleFailure(mocker.patch())
+	return mocker
+}
+
+func (mocker *Mocker) patch() error {
 	mocker.lock.Lock()
 	defer mocker.lock.Unlock()
 	if mocker.isPatched {
-		return mocker
+		return nil
 	}
 	runtimeTarget := mocker.builder.analyzer.RuntimeTargetValue()
-	mocker.patch = monkey.PatchValue(runtimeTarget, mocker.hook, mocker.proxy, mocker.builder.unsafe)
+	if err := catch(ErrTargetTooShort, func() { monkey.CheckValue(runtimeTarget, mocker.builder.unsafe) }); err != nil {
+		return mocker.builder.stampError(err)
+	}
+	if err := addToGlobal(mocker, func() error {
+		return catch(nil, func() {
+			mocker.pushLayer(runtimeTarget)
+		})
+	}); err != nil {
+		return mocker.builder.stampError(err)
+	}
 	mocker.isPatched = true
-	addToGlobal(mocker)
 
 	mocker.outerCaller = tool.OuterCaller()
-	return mocker
+	return nil
 }
 
 func (mocker *Mocker) UnPatch() *Mocker {
@@ -316,12 +578,43 @@ func (mocker *Mocker) UnPatch() *Mocker {
 	if !mocker.isPatched {
 		return mocker
 	}
-	mocker.patch.Unpatch()
+	mocker.popLayer()
 	mocker.isPatched = false
 	removeFromGlobal(mocker)
+	mocker.reset()
+
+	return mocker
+}
+
+// reset clears the counters and the call records
+func (mocker *Mocker) reset() {
 	atomic.StoreInt64(&mocker.times, 0)
 	atomic.StoreInt64(&mocker.mockTimes, 0)
+	for i := range mocker.condTimes {
+		atomic.StoreInt64(&mocker.condTimes[i], 0)
+	}
+	atomic.StoreInt64(&mocker.missTimes, 0)
+	mocker.resetCalls()
+}
+
+// Pause makes the target call the origin function directly until Resume is called, without unpatching the target.
+// The calls during the pause are neither counted nor recorded, and the existing counters and records are kept.
+//
+// For example:
+//
+//	mocker := Mock(Fun).Return("mocked").Build()
+//	mocker.Pause()
+//	Fun("a") // returns "a"
+//	mocker.Resume()
+//	Fun("a") // returns "mocked"
+func (mocker *Mocker) Pause() *Mocker {
+	atomic.StoreInt32(&mocker.paused, 1)
+	return mocker
+}
 
+// Resume makes the paused mock take effect again, see Pause
+func (mocker *Mocker) Resume() *Mocker {
+	atomic.StoreInt32(&mocker.paused, 0)
 	return mocker
 }
 
@@ -332,58 +625,119 @@ func (mocker *Mocker) Release() *MockBuilder {
 }
 
 func (mocker *Mocker) ExcludeCurrentGoRoutine() *Mocker {
-	return mocker.rePatch(func() {
+	return mocker.rePatch(func() error {
 		mocker.builder.ExcludeCurrentGoRoutine()
+		return nil
 	})
 }
 
 func (mocker *Mocker) FilterGoRoutine(filter FilterGoroutineType, gId int64) *Mocker {
-	return mocker.rePatch(func() {
+	return mocker.rePatch(func() error {
 		mocker.builder.FilterGoRoutine(filter, gId)
+		return nil
+	})
+}
+
+func (mocker *Mocker) IncludeCurrentGoRoutineTree() *Mocker {
+	return mocker.rePatch(func() error {
+		mocker.builder.IncludeCurrentGoRoutineTree()
+		return nil
+	})
+}
+
+func (mocker *Mocker) FilterGoRoutines(filter FilterGoroutineType, gIds ...int64) *Mocker {
+	return mocker.rePatch(func() error {
+		mocker.builder.FilterGoRoutines(filter, gIds...)
+		return nil
+	})
+}
+
+func (mocker *Mocker) FilterGoRoutineFunc(filter func(gId int64) bool) *Mocker {
+	return mocker.rePatch(func() error {
+		mocker.builder.FilterGoRoutineFunc(filter)
+		return nil
+	})
+}
+
+func (mocker *Mocker) FilterByLabel(key, value string) *Mocker {
+	return mocker.rePatch(func() error {
+		mocker.builder.FilterByLabel(key, value)
+		return nil
 	})
 }
 
 func (mocker *Mocker) IncludeCurrentGoRoutine() *Mocker {
-	return mocker.rePatch(func() {
+	return mocker.rePatch(func() error {
 		mocker.builder.IncludeCurrentGoRoutine()
+		return nil
 	})
 }
 
 func (mocker *Mocker) When(when interface{}) *Mocker {
-	tool.Assert(len(mocker.builder.conditions) == 1, "only one-condition mocker could reset when (You can call Release first, then rebuild mocker)")
+	if len(mocker.builder.conditions) != 1 {
+		handleFailure(mocker.builder.stampError(newMockError(ErrInvalidUsage, "only one-condition mocker could reset when (You can call Release first, then rebuild mocker)")))
+		return mocker
+	}
 
-	return mocker.rePatch(func() {
-		mocker.builder.conditions[0].SetWhenForce(when)
+	return mocker.rePatch(func() error {
+		return mocker.builder.conditions[0].SetWhenForce(when)
 	})
 }
 
 func (mocker *Mocker) To(to interface{}) *Mocker {
-	tool.Assert(len(mocker.builder.conditions) == 1, "only one-condition mocker could reset to  (You can call Release first, then rebuild mocker)")
+	if len(mocker.builder.conditions) != 1 {
+		handleFailure(mocker.builder.stampError(newMockError(ErrInvalidUsage, "only one-condition mocker could reset to  (You can call Release first, then rebuild mocker)")))
+		return mocker
+	}
 
-	return mocker.rePatch(func() {
-		mocker.builder.conditions[0].SetToForce(to)
+	return mocker.rePatch(func() error {
+		return mocker.builder.conditions[0].SetToForce(to)
 	})
 }
 
 func (mocker *Mocker) Return(results ...interface{}) *Mocker {
-	tool.Assert(len(mocker.builder.conditions) == 1, "only one-condition mocker could reset return  (You can call Release first, then rebuild mocker)")
+	if len(mocker.builder.conditions) != 1 {
+		handleFailure(mocker.builder.stampError(newMockError(ErrInvalidUsage, "only one-condition mocker could reset return  (You can call Release first, then rebuild mocker)")))
+		return mocker
+	}
 
-	return mocker.rePatch(func() {
-		mocker.builder.conditions[0].SetReturnForce(results...)
+	return mocker.rePatch(func() error {
+		return mocker.builder.conditions[0].SetReturnForce(results...)
 	})
 }
 
 func (mocker *Mocker) Origin(funcPtr interface{}) *Mocker {
-	return mocker.rePatch(func() {
-		mocker.builder.origin(funcPtr)
+	return mocker.rePatch(func() error {
+		return mocker.builder.origin(funcPtr)
 	})
 }
 
-func (mocker *Mocker) rePatch(do func()) *Mocker {
-	mocker.UnPatch()
-	do()
-	mocker.build()
-	mocker.Patch()
+// rePatch unpatches the mocker, modifies the builder by do and patches it again. The failure is reported by the
+// failure handler, and the mocker is left unpatched.
+// rePatch rebuilds the hook after do changes the builder. A patched mocker keeps its layer, whose hook is replaced in
+// place, so the layers above it still take effect. The mocker is unpatched if the rebuilding fails.
+func (mocker *Mocker) rePatch(do func() error) *Mocker {
+	mocker.lock.Lock()
+	patched := mocker.isPatched
+	mocker.lock.Unlock()
+	if err := do(); err != nil {
+		mocker.UnPatch()
+		handleFailure(mocker.builder.stampError(err))
+		return mocker
+	}
+	oldHook := mocker.hook
+	if err := catch(ErrSignatureMismatch, mocker.build); err != nil {
+		mocker.UnPatch()
+		handleFailure(mocker.builder.stampError(err))
+		return mocker
+	}
+	if !patched {
+		return mocker.Patch()
+	}
+	mocker.replaceLayer()
+	// the old hook is jumped into until it's replaced, which is invisible to the GC
+	runtime.KeepAlive(oldHook)
+	mocker.reset()
 	return mocker
 }
 
@@ -403,12 +757,28 @@ func (mocker *Mocker) MockTimes() int {
 	return int(atomic.LoadInt64(&mocker.mockTimes))
 }
 
+// ConditionTimes returns the times the condition named by MockBuilder.Named is matched
+func (mocker *Mocker) ConditionTimes(name string) int {
+	for i, cond := range mocker.builder.conditions {
+		if cond.name == name && i < len(mocker.condTimes) {
+			return int(atomic.LoadInt64(&mocker.condTimes[i]))
+		}
+	}
+	tool.Assert(false, "condition %s not found", name)
+	return 0
+}
+
+// UnmatchedTimes returns the times no condition is matched and the origin is called
+func (mocker *Mocker) UnmatchedTimes() int {
+	return int(atomic.LoadInt64(&mocker.missTimes))
+}
+
 func (mocker *Mocker) key() uintptr {
 	return mocker.target.Pointer()
 }
 
 func (mocker *Mocker) name() string {
-	return mocker.target.String()
+	return mocker.builder.name()
 }
 
 func (mocker *Mocker) unPatch() {
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/bytedance/mockey/internal/tool"
+)
+
+// CallRecord is the record of a single invocation of the mock target.
+type CallRecord struct {
+	Args        []interface{} // input arguments, the receiver is included if the target is a method
+	Results     []interface{} // returned values, empty if the call panicked
+	Panic       interface{}   // recovered value if the call panicked, the panic is propagated to the caller
+	Condition   int           // index of the matched condition, -1 if no condition matched and the origin was called
+	GoroutineID int64         // goroutine that made the call
+	Time        time.Time     // time when the call started
+	Caller      runtime.Frame // frame where the target was called
+	Seq         int64         // sequence number of the call, monotonically increasing across all mockers
+}
+
+// gCallSeq is the last sequence number stamped on a call record
+var gCallSeq int64
+
+// defaultCallLimit is the default max number of the call records of a mocker, see MockBuilder.RecordCalls
+const defaultCallLimit = 1024
+
+// RecordCalls sets the max number of the call records kept by the mocker, the calls beyond the limit are still counted
+// but not recorded. The records hold the arguments and results of the calls, so a limit keeps them from growing without
+// bound when the target is called a lot. 0 disables the recording, and a negative limit means no limit. The default
+// limit is 1024.
+//
+// For example:
+//
+//	Mock(Write).Return(nil).RecordCalls(0).Build() // Calls always returns nothing
+func (builder *MockBuilder) RecordCalls(limit int) *MockBuilder {
+	builder.callLimit = limit
+	return builder
+}
+
+// Calls returns the invocation records of the mocker in calling order, i.e. ordered by CallRecord.Seq, even if the
+// calls are nested or recursive. Records are cleared when the mocker is unpatched. Only the first calls are recorded if
+// the calls exceed the limit, see MockBuilder.RecordCalls.
+//
+// For example:
+//
+//	mocker := Mock(Fun).Return("mocked").Build()
+//	Fun("a")
+//	calls := mocker.Calls() // calls[0].Args == []interface{}{"a"}, calls[0].Results == []interface{}{"mocked"}
+func (mocker *Mocker) Calls() []CallRecord {
+	mocker.callsLock.Lock()
+	defer mocker.callsLock.Unlock()
+	res := make([]CallRecord, len(mocker.calls))
+	copy(res, mocker.calls)
+	return res
+}
+
+// recording reports whether a new call can be recorded under the limit. The calls of a spy are captured if so, since
+// whether they match is only known after they return.
+func (mocker *Mocker) recording() bool {
+	limit := mocker.builder.callLimit
+	if limit <= 0 {
+		return limit < 0
+	}
+	mocker.callsLock.Lock()
+	defer mocker.callsLock.Unlock()
+	return len(mocker.calls) < limit
+}
+
+func (mocker *Mocker) newCall(args []interface{}, caller tool.CallerInfo) *CallRecord {
+	return &CallRecord{
+		Args:        args,
+		Condition:   -1,
+		GoroutineID: tool.GetGoroutineID(),
+		Time:        tool.Now(),
+		Caller:      runtime.Frame(caller),
+		Seq:         atomic.AddInt64(&gCallSeq, 1),
+	}
+}
+
+// record stores the call ordered by the sequence number, since the nested calls return before their callers
+func (mocker *Mocker) record(call *CallRecord, results []reflect.Value) {
+	if mocker.builder.spy && call.Condition < 0 {
+		// spies only record the matched calls
+		return
+	}
+	limit := mocker.builder.callLimit
+	mocker.callsLock.Lock()
+	defer mocker.callsLock.Unlock()
+	if limit >= 0 && len(mocker.calls) >= limit {
+		return
+	}
+	call.Results = valuesToInterfaces(results)
+	i := len(mocker.calls)
+	for i > 0 && mocker.calls[i-1].Seq > call.Seq {
+		i--
+	}
+	mocker.calls = append(mocker.calls, CallRecord{})
+	copy(mocker.calls[i+1:], mocker.calls[i:])
+	mocker.calls[i] = *call
+}
+
+func (mocker *Mocker) resetCalls() {
+	mocker.callsLock.Lock()
+	mocker.calls = nil
+	mocker.callsLock.Unlock()
+}
+
+func valuesToInterfaces(values []reflect.Value) []interface{} {
+	res := make([]interface{}, len(values))
+	for i, v := range values {
+		res[i] = v.Interface()
+	}
+	return res
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/bytedance/mockey/internal/tool"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+//go:noinline
+func callFactorial(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return n * callFactorial(n-1)
+}
+
+func TestCalls(t *testing.T) {
+	PatchConvey("TestCalls", t, func() {
+		PatchConvey("record", func() {
+			mocker := Mock(Fun).When(func(a string) bool { return a == "a" }).Return("mocked").Build()
+			Fun("a")
+			caller := tool.Caller()
+			caller.Line -= 1
+			Fun("b")
+
+			calls := mocker.Calls()
+			convey.So(calls, convey.ShouldHaveLength, 2)
+			convey.So(calls[0].Args, convey.ShouldResemble, []interface{}{"a"})
+			convey.So(calls[0].Results, convey.ShouldResemble, []interface{}{"mocked"})
+			convey.So(calls[0].Condition, convey.ShouldEqual, 0)
+			convey.So(calls[0].GoroutineID, convey.ShouldEqual, tool.GetGoroutineID())
+			convey.So(calls[0].Caller.File, convey.ShouldEqual, caller.File)
+			convey.So(calls[0].Caller.Line, convey.ShouldEqual, caller.Line)
+			convey.So(calls[1].Args, convey.ShouldResemble, []interface{}{"b"})
+			convey.So(calls[1].Results, convey.ShouldResemble, []interface{}{"b"})
+			convey.So(calls[1].Condition, convey.ShouldEqual, -1)
+			convey.So(calls[1].Time.Before(calls[0].Time), convey.ShouldBeFalse)
+		})
+		PatchConvey("method and variadic", func() {
+			mocker := Mock((*Class).VariantParam).Return("mocked").Build()
+			c := &Class{}
+			c.VariantParam("a", "b", "c")
+
+			calls := mocker.Calls()
+			convey.So(calls, convey.ShouldHaveLength, 1)
+			convey.So(calls[0].Args, convey.ShouldResemble, []interface{}{c, "a", []string{"b", "c"}})
+		})
+		PatchConvey("concurrent", func() {
+			mocker := Mock(Fun).Return("mocked").Build()
+			wg := sync.WaitGroup{}
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					Fun("a")
+				}()
+			}
+			wg.Wait()
+			convey.So(mocker.Calls(), convey.ShouldHaveLength, 10)
+		})
+		PatchConvey("panic", func() {
+			mocker := Mock(Fun).To(func(a string) string { panic("boom") }).Build()
+			convey.So(func() { Fun("a") }, convey.ShouldPanicWith, "boom")
+
+			calls := mocker.Calls()
+			convey.So(calls, convey.ShouldHaveLength, 1)
+			convey.So(calls[0].Args, convey.ShouldResemble, []interface{}{"a"})
+			convey.So(calls[0].Results, convey.ShouldBeEmpty)
+			convey.So(calls[0].Panic, convey.ShouldEqual, "boom")
+		})
+		PatchConvey("spy", func() {
+			mocker := Mock(Fun).Spy().Build()
+			convey.So(Fun("a"), convey.ShouldEqual, "a")
+
+			calls := mocker.Calls()
+			convey.So(calls, convey.ShouldHaveLength, 1)
+			convey.So(calls[0].Results, convey.ShouldResemble, []interface{}{"a"})
+			convey.So(mocker.Times(), convey.ShouldEqual, 1)
+			convey.So(mocker.MockTimes(), convey.ShouldEqual, 0)
+		})
+		PatchConvey("spy with when", func() {
+			mocker := Mock(Fun).When(func(a string) bool { return a == "a" }).Spy().Build()
+			convey.So(Fun("a"), convey.ShouldEqual, "a")
+			convey.So(Fun("b"), convey.ShouldEqual, "b")
+
+			calls := mocker.Calls()
+			convey.So(calls, convey.ShouldHaveLength, 1)
+			convey.So(calls[0].Args, convey.ShouldResemble, []interface{}{"a"})
+			convey.So(calls[0].Condition, convey.ShouldEqual, 0)
+		})
+		PatchConvey("spy with hook", func() {
+			_, err := Mock(Fun).Return("mocked").Spy().TryBuild()
+			convey.So(errors.Is(err, ErrInvalidUsage), convey.ShouldBeTrue)
+			_, err = Mock(Fun).Spy().To(func(a string) string { return a }).TryBuild()
+			convey.So(errors.Is(err, ErrInvalidUsage), convey.ShouldBeTrue)
+		})
+		PatchConvey("reset on unpatch", func() {
+			mocker := Mock(Fun).Return("mocked").Build()
+			Fun("a")
+			convey.So(mocker.Calls(), convey.ShouldHaveLength, 1)
+			mocker.UnPatch()
+			convey.So(mocker.Calls(), convey.ShouldHaveLength, 0)
+		})
+		PatchConvey("limit", func() {
+			mocker := Mock(Fun).Return("mocked").RecordCalls(2).Build()
+			Fun("a")
+			Fun("b")
+			Fun("c")
+			convey.So(mocker.Times(), convey.ShouldEqual, 3)
+			calls := mocker.Calls()
+			convey.So(calls, convey.ShouldHaveLength, 2)
+			convey.So(calls[1].Args, convey.ShouldResemble, []interface{}{"b"})
+
+			mocker.UnPatch()
+			mocker = Mock(Fun).Return("mocked").RecordCalls(0).Build()
+			Fun("a")
+			convey.So(mocker.Times(), convey.ShouldEqual, 1)
+			convey.So(mocker.Calls(), convey.ShouldHaveLength, 0)
+		})
+		PatchConvey("nested", func() {
+			var origin func(int) int
+			mocker := Mock(callFactorial).Origin(&origin).To(func(n int) int { return origin(n) }).Build()
+			convey.So(callFactorial(3), convey.ShouldEqual, 6)
+			calls := mocker.Calls()
+			convey.So(calls, convey.ShouldHaveLength, 3)
+			for i, n := range []int{3, 2, 1} {
+				convey.So(calls[i].Args, convey.ShouldResemble, []interface{}{n})
+			}
+			convey.So(calls[0].Seq, convey.ShouldBeLessThan, calls[1].Seq)
+			convey.So(calls[1].Seq, convey.ShouldBeLessThan, calls[2].Seq)
+			convey.So(calls[0].Results, convey.ShouldResemble, []interface{}{6})
+		})
+	})
+}
@@ -36,35 +36,31 @@ func (c *callerStruct) Foo() {
 
 var callerValue string
 
-func TestReMockPanic(t *testing.T) {
-	PatchConvey("TestReMockPanic", t, func() {
+func TestReMockInSameScope(t *testing.T) {
+	PatchConvey("TestReMockInSameScope", t, func() {
 		PatchConvey("callerFunc", func() {
 			mocker := Mock(callerFunc).To(func() { fmt.Println("should not panic") }).Build()
 			mocker.To(func() { fmt.Println("should also not panic") })
-			lastCaller := tool.Caller()
-			lastCaller.Line -= 1
 			var err interface{}
 			func() {
 				defer func() { err = recover() }()
-				Mock(callerFunc).To(func() { fmt.Println("should panic, but recovered") }).Build()
+				Mock(callerFunc).To(func() { fmt.Println("should not panic, layered") }).Build()
+				callerFunc()
 			}()
-			errString, ok := err.(string)
-			convey.So(ok, convey.ShouldBeTrue)
-			convey.So(strings.Contains(errString, lastCaller.String()), convey.ShouldBeTrue)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(mocker.Times(), convey.ShouldEqual, 0)
 		})
 		PatchConvey("callerStruct", func() {
 			mocker := Mock((*callerStruct).Foo).To(func() { fmt.Println("should not panic") }).Build()
 			mocker.To(func() { fmt.Println("should also not panic") })
-			lastCaller := tool.Caller()
-			lastCaller.Line -= 1
 			var err interface{}
 			func() {
 				defer func() { err = recover() }()
-				Mock((*callerStruct).Foo).To(func() { fmt.Println("should panic, but recovered") }).Build()
+				Mock((*callerStruct).Foo).To(func() { fmt.Println("should not panic, layered") }).Build()
+				new(callerStruct).Foo()
 			}()
-			errString, ok := err.(string)
-			convey.So(ok, convey.ShouldBeTrue)
-			convey.So(strings.Contains(errString, lastCaller.String()), convey.ShouldBeTrue)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(mocker.Times(), convey.ShouldEqual, 0)
 		})
 		PatchConvey("callerValue", func() {
 			MockValue(&callerValue).To("should not panic")
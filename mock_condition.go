@@ -25,6 +25,9 @@ import (
 type mockCondition struct {
 	when interface{} // condition
 	hook interface{} // mock function
+	name string      // condition name, see MockBuilder.Named
+
+	effects []func(args []reflect.Value) // side effects on the arguments after the hook, see MockBuilder.SetArg and CallArg
 
 	builder *MockBuilder
 }
@@ -33,57 +36,177 @@ func (m *mockCondition) Complete() bool {
 	return m.when != nil && m.hook != nil
 }
 
-func (m *mockCondition) SetWhen(when interface{}) {
-	tool.Assert(m.when == nil, "re-set builder when")
-	m.SetWhenForce(when)
+func (m *mockCondition) SetWhen(when interface{}) error {
+	if m.when != nil {
+		return newMockError(ErrInvalidUsage, "re-set builder when")
+	}
+	return m.SetWhenForce(when)
 }
 
-func (m *mockCondition) SetWhenForce(when interface{}) {
+func (m *mockCondition) SetWhenForce(when interface{}) error {
 	wVal, wTyp := reflect.ValueOf(when), reflect.TypeOf(when)
-	tool.Assert(wTyp.NumOut() == 1, "when func ret value not bool")
+	if wTyp == nil || wTyp.Kind() != reflect.Func {
+		return newMockError(ErrNotFunction, "'%v' is not a function", when)
+	}
+	if wTyp.NumOut() != 1 || wTyp.Out(0).Kind() != reflect.Bool {
+		return newMockError(ErrSignatureMismatch, "when func ret value not bool")
+	}
 	out1 := wTyp.Out(0)
-	tool.Assert(out1.Kind() == reflect.Bool, "when func ret value not bool")
 
-	adapter := m.builder.analyzer.InputAdapter("when", wTyp)
+	var adapter func([]reflect.Value) []reflect.Value
+	if err := catch(ErrSignatureMismatch, func() { adapter = m.builder.analyzer.InputAdapter("when", wTyp) }); err != nil {
+		return err
+	}
 	runtimeWhenType := tool.NewFuncTypeByOut(m.builder.runtimeTargetType(), out1)
 
 	m.when = reflect.MakeFunc(runtimeWhenType, func(args []reflect.Value) []reflect.Value {
 		return tool.ReflectCall(wVal, adapter(args))
 	}).Interface()
+	return nil
 }
 
-func (m *mockCondition) SetReturn(results ...interface{}) {
-	tool.Assert(m.hook == nil, "re-set builder hook")
-	m.SetReturnForce(results...)
+func (m *mockCondition) SetReturn(results ...interface{}) error {
+	if m.hook != nil {
+		return newMockError(ErrInvalidUsage, "re-set builder hook")
+	}
+	return m.SetReturnForce(results...)
 }
 
-func (m *mockCondition) SetReturnForce(results ...interface{}) {
-	getResult := func() []interface{} { return results }
-	if len(results) == 1 {
-		seq, ok := results[0].(SequenceOpt)
-		if ok {
-			getResult = seq.GetNext
+func (m *mockCondition) SetReturnForce(results ...interface{}) error {
+	if m.builder.spy {
+		return newMockError(ErrInvalidUsage, "spy with mock hook")
+	}
+	hookType := m.builder.runtimeTargetType()
+	getResult := func([]reflect.Value) []interface{} { return results }
+	toHooks := map[*toResult]interface{}{}
+	if seq, ok := sequenceOf(results); ok {
+		// values of sequence are checked when they are returned, except the hooks of ThenTo, which are adapted once here
+		for _, r := range toResultsOf(seq) {
+			cond := m.builder.newCondition()
+			if err := cond.SetToForce(r.hook); err != nil {
+				return err
+			}
+			toHooks[r] = cond.hook
+		}
+		getResult = func([]reflect.Value) []interface{} { return seq.GetNext() }
+		if keyed, ok := seq.(*keyedSequence); ok {
+			var adapter func([]reflect.Value) []reflect.Value
+			if err := catch(ErrSignatureMismatch, func() { adapter = keyed.adapter(m.builder.analyzer) }); err != nil {
+				return err
+			}
+			getResult = func(args []reflect.Value) []interface{} { return keyed.next(adapter(args)) }
 		}
+	} else if err := catch(ErrSignatureMismatch, func() { resolveResults(hookType, results) }); err != nil {
+		return err
 	}
 
-	hookType := m.builder.runtimeTargetType()
-	m.hook = reflect.MakeFunc(hookType, func([]reflect.Value) []reflect.Value {
-		current := getResult()
-		tool.CheckReturnValues(hookType, current...)
+	m.hook = reflect.MakeFunc(hookType, func(args []reflect.Value) []reflect.Value {
+		current := getResult(args)
+		if len(current) == 1 {
+			switch r := current[0].(type) {
+			case originResult:
+				return m.builder.originExec(args)
+			case *toResult:
+				hook, ok := toHooks[r]
+				if !ok {
+					// appended after the mock is built
+					cond := m.builder.newCondition()
+					err := cond.SetToForce(r.hook)
+					tool.Assert(err == nil, "%v", err)
+					hook = cond.hook
+				}
+				return tool.ReflectCall(reflect.ValueOf(hook), args)
+			}
+		}
+		current, p := resolveResults(hookType, current)
+		if p != nil {
+			panic(p.value)
+		}
 		return tool.MakeReturnValues(hookType, current...)
 	}).Interface()
+	return nil
 }
 
-func (m *mockCondition) SetTo(to interface{}) {
-	tool.Assert(m.hook == nil, "re-set builder hook")
-	m.SetToForce(to)
+func (m *mockCondition) SetTo(to interface{}) error {
+	if m.hook != nil {
+		return newMockError(ErrInvalidUsage, "re-set builder hook")
+	}
+	return m.SetToForce(to)
 }
 
-func (m *mockCondition) SetToForce(to interface{}) {
+func (m *mockCondition) SetToForce(to interface{}) error {
+	if m.builder.spy {
+		return newMockError(ErrInvalidUsage, "spy with mock hook")
+	}
 	toType := reflect.TypeOf(to)
-	tool.CheckFuncReturnValues(m.builder.analyzer.TargetType(), toType)
-	adapter := m.builder.analyzer.InputAdapter("hook", toType)
+	if toType == nil || toType.Kind() != reflect.Func {
+		return newMockError(ErrNotFunction, "'%v' is not a function", to)
+	}
+	var adapter func([]reflect.Value) []reflect.Value
+	if err := catch(ErrSignatureMismatch, func() {
+		tool.CheckFuncReturnValues(m.builder.analyzer.TargetType(), toType)
+		adapter = m.builder.analyzer.InputAdapter("hook", toType)
+	}); err != nil {
+		return err
+	}
 	m.hook = reflect.MakeFunc(m.builder.runtimeTargetType(), func(args []reflect.Value) (results []reflect.Value) {
 		return tool.ReflectCall(reflect.ValueOf(to), adapter(args))
 	}).Interface()
+	return nil
+}
+
+// panicResult makes the hook panic with value, see MockBuilder.Panic
+type panicResult struct {
+	value interface{}
+}
+
+// errResult makes the hook return err in the last error result and zero values in the others, see MockBuilder.ReturnErr
+type errResult struct {
+	err error
+}
+
+// originResult makes the hook call the origin function, see sequence.ThenOrigin
+type originResult struct{}
+
+// toResult makes the hook call hook, see sequence.ThenTo
+type toResult struct {
+	hook interface{}
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// resolveResults resolves the results declared by Return, Panic or ReturnErr into the return values of hookType and
+// checks them. The panicResult is returned if the results are declared by Panic.
+func resolveResults(hookType reflect.Type, results []interface{}) ([]interface{}, *panicResult) {
+	if len(results) == 1 {
+		switch r := results[0].(type) {
+		case panicResult:
+			return nil, &r
+		case errResult:
+			idx := errorIndex(hookType)
+			tool.Assert(idx >= 0, "target func has no error return value")
+			results = make([]interface{}, hookType.NumOut())
+			results[idx] = r.err
+		}
+	}
+	tool.CheckReturnValues(hookType, results...)
+	return results, nil
+}
+
+// errorIndex returns the index of the last error result of hookType, -1 if there is none
+func errorIndex(hookType reflect.Type) int {
+	for i := hookType.NumOut() - 1; i >= 0; i-- {
+		if hookType.Out(i) == errorType {
+			return i
+		}
+	}
+	return -1
+}
+
+func sequenceOf(results []interface{}) (SequenceOpt, bool) {
+	if len(results) != 1 {
+		return nil, false
+	}
+	seq, ok := results[0].(SequenceOpt)
+	return seq, ok
 }
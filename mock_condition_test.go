@@ -17,7 +17,9 @@
 package mockey
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"testing"
 
 	"github.com/smartystreets/goconvey/convey"
@@ -93,24 +95,24 @@ func TestMultiCondition(t *testing.T) {
 				anyTo := func(i int) string { return "" }
 				anyReturn := ""
 				PatchConvey("when-when", func() {
-					convey.So(func() { builder.When(anyWhen).When(anyWhen) }, convey.ShouldPanic)
+					convey.So(func() { builder.When(anyWhen).When(anyWhen).Build() }, convey.ShouldPanic)
 				})
 				PatchConvey("to", func() {
 					builder.To(anyTo)
 					PatchConvey("to-to", func() {
-						convey.So(func() { builder.To(anyTo) }, convey.ShouldPanic)
+						convey.So(func() { builder.To(anyTo).Build() }, convey.ShouldPanic)
 					})
 					PatchConvey("to-return", func() {
-						convey.So(func() { builder.Return(anyReturn) }, convey.ShouldPanic)
+						convey.So(func() { builder.Return(anyReturn).Build() }, convey.ShouldPanic)
 					})
 				})
 				PatchConvey("return", func() {
 					builder.Return(anyReturn)
 					PatchConvey("return-to", func() {
-						convey.So(func() { builder.To(anyTo) }, convey.ShouldPanic)
+						convey.So(func() { builder.To(anyTo).Build() }, convey.ShouldPanic)
 					})
 					PatchConvey("return-return", func() {
-						convey.So(func() { builder.Return(anyReturn) }, convey.ShouldPanic)
+						convey.So(func() { builder.Return(anyReturn).Build() }, convey.ShouldPanic)
 					})
 				})
 			})
@@ -204,27 +206,102 @@ func TestMultiCondition(t *testing.T) {
 				anyTo := func(i int) string { return "" }
 				anyReturn := ""
 				PatchConvey("when-when", func() {
-					convey.So(func() { builder.When(anyWhen).When(anyWhen) }, convey.ShouldPanic)
+					convey.So(func() { builder.When(anyWhen).When(anyWhen).Build() }, convey.ShouldPanic)
 				})
 				PatchConvey("to", func() {
 					builder.To(anyTo)
 					PatchConvey("to-to", func() {
-						convey.So(func() { builder.To(anyTo) }, convey.ShouldPanic)
+						convey.So(func() { builder.To(anyTo).Build() }, convey.ShouldPanic)
 					})
 					PatchConvey("to-return", func() {
-						convey.So(func() { builder.Return(anyReturn) }, convey.ShouldPanic)
+						convey.So(func() { builder.Return(anyReturn).Build() }, convey.ShouldPanic)
 					})
 				})
 				PatchConvey("return", func() {
 					builder.Return(anyReturn)
 					PatchConvey("return-to", func() {
-						convey.So(func() { builder.To(anyTo) }, convey.ShouldPanic)
+						convey.So(func() { builder.To(anyTo).Build() }, convey.ShouldPanic)
 					})
 					PatchConvey("return-return", func() {
-						convey.So(func() { builder.Return(anyReturn) }, convey.ShouldPanic)
+						convey.So(func() { builder.Return(anyReturn).Build() }, convey.ShouldPanic)
 					})
 				})
 			})
 		})
 	})
 }
+
+func TestPanicAndReturnErr(t *testing.T) {
+	PatchConvey("panic and return err", t, func() {
+		fn := func(i int) (string, error) {
+			fmt.Println("original fn")
+			return "fn", nil
+		}
+
+		PatchConvey("panic", func() {
+			Mock(fn).When(func(i int) bool { return i < 0 }).Panic("negative").Build()
+			convey.So(func() { _, _ = fn(-1) }, convey.ShouldPanicWith, "negative")
+			res, err := fn(1)
+			convey.So(res, convey.ShouldEqual, "fn")
+			convey.So(err, convey.ShouldBeNil)
+		})
+		PatchConvey("return err", func() {
+			Mock(fn).ReturnErr(io.EOF).Build()
+			res, err := fn(1)
+			convey.So(res, convey.ShouldEqual, "")
+			convey.So(err, convey.ShouldEqual, io.EOF)
+		})
+		PatchConvey("sequence", func() {
+			Mock(fn).Return(Sequence("a", nil).ThenErr(io.EOF).ThenPanic("boom")).Build()
+			res, err := fn(1)
+			convey.So(res, convey.ShouldEqual, "a")
+			convey.So(err, convey.ShouldBeNil)
+			res, err = fn(1)
+			convey.So(res, convey.ShouldEqual, "")
+			convey.So(err, convey.ShouldEqual, io.EOF)
+			convey.So(func() { _, _ = fn(1) }, convey.ShouldPanicWith, "boom")
+		})
+		PatchConvey("no error result", func() {
+			_, err := Mock(Fun).ReturnErr(io.EOF).TryBuild()
+			convey.So(errors.Is(err, ErrSignatureMismatch), convey.ShouldBeTrue)
+		})
+	})
+}
+
+func TestNamedCondition(t *testing.T) {
+	PatchConvey("named condition", t, func() {
+		fn := func(i int) string {
+			fmt.Println("original fn")
+			return "fn"
+		}
+
+		PatchConvey("times", func() {
+			mocker := Mock(fn).
+				When(func(i int) bool { return i < 0 }).Named("negative").Return("negative").
+				When(func(i int) bool { return i == 0 }).Return("zero").Named("zero").
+				Build()
+			convey.So(fn(-1), convey.ShouldEqual, "negative")
+			convey.So(fn(-2), convey.ShouldEqual, "negative")
+			convey.So(fn(0), convey.ShouldEqual, "zero")
+			convey.So(fn(1), convey.ShouldEqual, "fn")
+			convey.So(mocker.ConditionTimes("negative"), convey.ShouldEqual, 2)
+			convey.So(mocker.ConditionTimes("zero"), convey.ShouldEqual, 1)
+			convey.So(mocker.UnmatchedTimes(), convey.ShouldEqual, 1)
+			convey.So(mocker.MockTimes(), convey.ShouldEqual, 3)
+			convey.So(func() { mocker.ConditionTimes("positive") }, convey.ShouldPanicWith, "condition positive not found")
+
+			mocker.UnPatch()
+			convey.So(mocker.ConditionTimes("negative"), convey.ShouldEqual, 0)
+			convey.So(mocker.UnmatchedTimes(), convey.ShouldEqual, 0)
+		})
+		PatchConvey("invalid", func() {
+			_, err := Mock(fn).Return("a").Named("").TryBuild()
+			convey.So(errors.Is(err, ErrInvalidUsage), convey.ShouldBeTrue)
+			_, err = Mock(fn).
+				When(func(i int) bool { return i < 0 }).Named("a").Return("a").
+				When(func(i int) bool { return i > 0 }).Named("a").Return("b").
+				TryBuild()
+			convey.So(errors.Is(err, ErrInvalidUsage), convey.ShouldBeTrue)
+		})
+	})
+}
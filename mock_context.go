@@ -0,0 +1,55 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"context"
+)
+
+// mockContextKey is the context key of the mocker, see WithMock
+type mockContextKey struct {
+	mocker *Mocker
+}
+
+// ForContext makes the mock only take effect when one of the arguments of the target is a context.Context carrying the
+// mocker, see WithMock. The other calls go to the origin function.
+//
+// For example:
+//
+//	mocker := Mock(Fun).ForContext().Return("mocked").Build()
+//	ctx := WithMock(context.Background(), mocker)
+//	Fun(ctx, "a")                  // mocked
+//	Fun(context.Background(), "a") // not mocked
+func (builder *MockBuilder) ForContext() *MockBuilder {
+	builder.forContext = true
+	return builder
+}
+
+// WithMock returns a copy of ctx carrying the mocker, in which the mocker built with ForContext takes effect
+func WithMock(ctx context.Context, mocker *Mocker) context.Context {
+	return context.WithValue(ctx, mockContextKey{mocker: mocker}, true)
+}
+
+// inContext reports whether one of args is a context.Context carrying the mocker
+func (mocker *Mocker) inContext(args []interface{}) bool {
+	for _, arg := range args {
+		if ctx, ok := arg.(context.Context); ok && ctx != nil && ctx.Value(mockContextKey{mocker: mocker}) != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func contextFun(ctx context.Context, a string) string {
+	fmt.Println(a)
+	return a
+}
+
+func TestForContext(t *testing.T) {
+	PatchConvey("TestForContext", t, func(c convey.C) {
+		mocker := Mock(contextFun).ForContext().Return("mocked").Build()
+		ctx := WithMock(context.Background(), mocker)
+		convey.So(contextFun(ctx, "a"), convey.ShouldEqual, "mocked")
+		child, cancel := context.WithCancel(ctx)
+		defer cancel()
+		convey.So(contextFun(child, "a"), convey.ShouldEqual, "mocked")
+		convey.So(contextFun(context.Background(), "a"), convey.ShouldEqual, "a")
+		convey.So(contextFun(nil, "a"), convey.ShouldEqual, "a")
+		convey.So(mocker.Times(), convey.ShouldEqual, 4)
+		convey.So(mocker.MockTimes(), convey.ShouldEqual, 2)
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if i%2 == 0 {
+					c.So(contextFun(ctx, "a"), convey.ShouldEqual, "mocked")
+				} else {
+					c.So(contextFun(context.Background(), "a"), convey.ShouldEqual, "a")
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+}
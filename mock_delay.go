@@ -0,0 +1,104 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/bytedance/mockey/internal/tool"
+)
+
+// Delay makes every call of the target sleep for d before calling the hook or the origin.
+//
+// If one of the arguments is a context.Context which is done during the delay, the delay is aborted and the target
+// returns ctx.Err() in its last result of type error and zero values in the others. If the target has no error result,
+// the call goes on after the delay is aborted.
+//
+// For example:
+//
+//	Mock(Fun).Delay(200 * time.Millisecond).Return("mocked").Build()
+func (builder *MockBuilder) Delay(d time.Duration) *MockBuilder {
+	return builder.do(func() error {
+		if d < 0 {
+			return newMockError(ErrInvalidUsage, "delay should not be negative")
+		}
+		builder.delay = func() time.Duration { return d }
+		return nil
+	})
+}
+
+// Jitter is like Delay, but the delay of each call is chosen randomly in [min, max] by a random source with seed, so
+// that the delays are reproducible.
+func (builder *MockBuilder) Jitter(min, max time.Duration, seed int64) *MockBuilder {
+	return builder.do(func() error {
+		if min < 0 || min > max {
+			return newMockError(ErrInvalidUsage, "invalid jitter range [%v, %v]", min, max)
+		}
+		var lock sync.Mutex
+		r := rand.New(rand.NewSource(seed))
+		builder.delay = func() time.Duration {
+			lock.Lock()
+			defer lock.Unlock()
+			return min + time.Duration(r.Int63n(int64(max-min)+1))
+		}
+		return nil
+	})
+}
+
+// wait sleeps for the delay before a call with args. If the delay is aborted by a context in args, it returns the
+// results with the context error and true.
+func (builder *MockBuilder) wait(args []interface{}) ([]reflect.Value, bool) {
+	if builder.delay == nil {
+		return nil, false
+	}
+	// time.NewTimer may be mocked, e.g. by the clock package, so the delay should not rely on it
+	newTimer := originOf(time.NewTimer).(func(time.Duration) *time.Timer)
+	timer := newTimer(builder.delay())
+	defer timer.Stop()
+
+	ctx := contextOf(args)
+	if ctx == nil {
+		<-timer.C
+		return nil, false
+	}
+	select {
+	case <-timer.C:
+		return nil, false
+	case <-ctx.Done():
+	}
+
+	hookType := builder.runtimeTargetType()
+	if errorIndex(hookType) < 0 {
+		return nil, false
+	}
+	results, _ := resolveResults(hookType, []interface{}{errResult{err: ctx.Err()}})
+	return tool.MakeReturnValues(hookType, results...), true
+}
+
+// contextOf returns the first context.Context in args, nil if there is none
+func contextOf(args []interface{}) context.Context {
+	for _, arg := range args {
+		if ctx, ok := arg.(context.Context); ok && ctx != nil {
+			return ctx
+		}
+	}
+	return nil
+}
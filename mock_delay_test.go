@@ -0,0 +1,83 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func delayFun(ctx context.Context, a string) (string, error) {
+	fmt.Println(a)
+	return a, ctx.Err()
+}
+
+func TestDelay(t *testing.T) {
+	PatchConvey("TestDelay", t, func() {
+		PatchConvey("delay", func() {
+			Mock(delayFun).Delay(50*time.Millisecond).Return("mocked", nil).Build()
+			start := time.Now()
+			res, err := delayFun(context.Background(), "a")
+			convey.So(time.Since(start), convey.ShouldBeGreaterThanOrEqualTo, 50*time.Millisecond)
+			convey.So(res, convey.ShouldEqual, "mocked")
+			convey.So(err, convey.ShouldBeNil)
+		})
+		PatchConvey("origin", func() {
+			Mock(Fun).Delay(50 * time.Millisecond).Build()
+			start := time.Now()
+			convey.So(Fun("a"), convey.ShouldEqual, "a")
+			convey.So(time.Since(start), convey.ShouldBeGreaterThanOrEqualTo, 50*time.Millisecond)
+		})
+		PatchConvey("context canceled", func() {
+			mocker := Mock(delayFun).Delay(time.Hour).Return("mocked", nil).Build()
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			res, err := delayFun(ctx, "a")
+			convey.So(res, convey.ShouldEqual, "")
+			convey.So(errors.Is(err, context.DeadlineExceeded), convey.ShouldBeTrue)
+			convey.So(mocker.MockTimes(), convey.ShouldEqual, 0)
+		})
+		PatchConvey("timer mocked", func() {
+			never := time.NewTimer(time.Hour)
+			defer never.Stop()
+			Mock(time.NewTimer).Return(never).Build()
+			Mock(Fun).Delay(50 * time.Millisecond).Return("mocked").Build()
+			start := time.Now()
+			convey.So(Fun("a"), convey.ShouldEqual, "mocked")
+			convey.So(time.Since(start), convey.ShouldBeGreaterThanOrEqualTo, 50*time.Millisecond)
+		})
+		PatchConvey("jitter", func() {
+			Mock(Fun).Jitter(10*time.Millisecond, 30*time.Millisecond, 1).Return("mocked").Build()
+			for i := 0; i < 3; i++ {
+				start := time.Now()
+				convey.So(Fun("a"), convey.ShouldEqual, "mocked")
+				convey.So(time.Since(start), convey.ShouldBeGreaterThanOrEqualTo, 10*time.Millisecond)
+			}
+		})
+		PatchConvey("invalid", func() {
+			_, err := Mock(Fun).Delay(-time.Second).Return("mocked").TryBuild()
+			convey.So(errors.Is(err, ErrInvalidUsage), convey.ShouldBeTrue)
+			_, err = Mock(Fun).Jitter(time.Second, time.Millisecond, 1).Return("mocked").TryBuild()
+			convey.So(errors.Is(err, ErrInvalidUsage), convey.ShouldBeTrue)
+		})
+	})
+}
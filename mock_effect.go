@@ -0,0 +1,119 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"reflect"
+
+	"github.com/bytedance/mockey/internal/tool"
+)
+
+// SetArg assigns value through the pointer argument at index after the hook of the condition being declared, or the
+// last declared one, is executed. The index is the same as the one in CallRecord.Args, which counts the receiver of a
+// method but not the generic type info.
+//
+// For example:
+//
+//	func Load(key string, out *Config) error
+//	Mock(Load).Return(nil).SetArg(1, Config{Name: "mocked"}).Build()
+func (builder *MockBuilder) SetArg(index int, value interface{}) *MockBuilder {
+	return builder.do(func() error {
+		return builder.addEffect(index, func(argType reflect.Type) (func(arg reflect.Value), error) {
+			if argType.Kind() != reflect.Ptr {
+				return nil, newMockError(ErrSignatureMismatch, "arg %d is not a pointer: %v", index, argType)
+			}
+			v, err := convertValue(value, argType.Elem())
+			if err != nil {
+				return nil, err
+			}
+			return func(arg reflect.Value) {
+				tool.Assert(!arg.IsNil(), "arg %d is nil", index)
+				arg.Elem().Set(v)
+			}, nil
+		})
+	})
+}
+
+// CallArg calls the function argument at index with args after the hook of the condition being declared, or the last
+// declared one, is executed. The index is the same as SetArg.
+//
+// For example:
+//
+//	func Walk(root string, fn func(path string) error) error
+//	Mock(Walk).Return(nil).CallArg(1, "a.txt").Build()
+func (builder *MockBuilder) CallArg(index int, args ...interface{}) *MockBuilder {
+	return builder.do(func() error {
+		return builder.addEffect(index, func(argType reflect.Type) (func(arg reflect.Value), error) {
+			if argType.Kind() != reflect.Func {
+				return nil, newMockError(ErrSignatureMismatch, "arg %d is not a function: %v", index, argType)
+			}
+			if argType.IsVariadic() || argType.NumIn() != len(args) {
+				return nil, newMockError(ErrSignatureMismatch, "args not match: arg %d: %v, args count: %d", index, argType, len(args))
+			}
+			in := make([]reflect.Value, len(args))
+			for i, a := range args {
+				v, err := convertValue(a, argType.In(i))
+				if err != nil {
+					return nil, err
+				}
+				in[i] = v
+			}
+			return func(arg reflect.Value) {
+				tool.Assert(!arg.IsNil(), "arg %d is nil", index)
+				tool.ReflectCall(arg, in)
+			}, nil
+		})
+	})
+}
+
+// addEffect adds the side effect on the argument at index to the last condition, newEffect checks the argument type
+// and returns the side effect
+func (builder *MockBuilder) addEffect(index int, newEffect func(argType reflect.Type) (func(arg reflect.Value), error)) error {
+	targetType := builder.analyzer.TargetType()
+	if index < 0 || index >= targetType.NumIn() {
+		return newMockError(ErrSignatureMismatch, "arg index %d out of range: target: %v", index, targetType)
+	}
+	effect, err := newEffect(targetType.In(index))
+	if err != nil {
+		return err
+	}
+	var adapter func([]reflect.Value) []reflect.Value
+	if err := catch(ErrSignatureMismatch, func() { adapter = builder.analyzer.InputAdapter("arg", targetType) }); err != nil {
+		return err
+	}
+	cond := builder.conditions[len(builder.conditions)-1]
+	cond.effects = append(cond.effects, func(args []reflect.Value) { effect(adapter(args)[index]) })
+	return nil
+}
+
+// convertValue converts value to typ, nil is converted to the zero value. The value should be assignable to typ, or a
+// number or string which can be converted to typ without changing its value like an untyped constant, e.g. 1 to int64.
+func convertValue(value interface{}, typ reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Zero(typ), nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(typ) {
+		return v.Convert(typ), nil
+	}
+	if class := kindClass(v.Kind()); class != 0 && class == kindClass(typ.Kind()) {
+		if converted := v.Convert(typ); isLossless(v, converted) {
+			return converted, nil
+		}
+	}
+	return reflect.Value{}, newMockError(ErrSignatureMismatch, "value %v of type %v is not assignable to %v", value, v.Type(), typ)
+}
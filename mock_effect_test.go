@@ -0,0 +1,119 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+type effectConfig struct {
+	Name string
+}
+
+func effectLoad(key string, out *effectConfig) error {
+	fmt.Println(key)
+	out.Name = "origin"
+	return nil
+}
+
+func effectWalk(root string, fn func(path string, depth int) error) error {
+	fmt.Println(root)
+	return fn(root, 0)
+}
+
+func effectScan(name *string, size *int8, ratio *float64) {
+	fmt.Println(*name, *size, *ratio)
+}
+
+type effectStore struct{}
+
+func (s *effectStore) Load(key string, out *effectConfig) error {
+	fmt.Println(key)
+	return nil
+}
+
+func TestEffect(t *testing.T) {
+	PatchConvey("TestEffect", t, func() {
+		PatchConvey("set arg", func() {
+			Mock(effectLoad).Return(nil).SetArg(1, effectConfig{Name: "mocked"}).Build()
+			var cfg effectConfig
+			convey.So(effectLoad("a", &cfg), convey.ShouldBeNil)
+			convey.So(cfg.Name, convey.ShouldEqual, "mocked")
+		})
+		PatchConvey("set arg with when", func() {
+			Mock(effectLoad).
+				When(func(key string, out *effectConfig) bool { return key == "a" }).SetArg(1, effectConfig{Name: "a"}).Return(nil).
+				When(func(key string, out *effectConfig) bool { return key == "b" }).Return(errors.New("b")).SetArg(1, nil).
+				Build()
+			cfg := effectConfig{Name: "init"}
+			convey.So(effectLoad("a", &cfg), convey.ShouldBeNil)
+			convey.So(cfg.Name, convey.ShouldEqual, "a")
+			convey.So(effectLoad("b", &cfg), convey.ShouldNotBeNil)
+			convey.So(cfg.Name, convey.ShouldEqual, "")
+			convey.So(effectLoad("c", &cfg), convey.ShouldBeNil)
+			convey.So(cfg.Name, convey.ShouldEqual, "origin")
+		})
+		PatchConvey("set arg of method", func() {
+			Mock((*effectStore).Load).Return(nil).SetArg(2, effectConfig{Name: "mocked"}).Build()
+			var cfg effectConfig
+			convey.So((&effectStore{}).Load("a", &cfg), convey.ShouldBeNil)
+			convey.So(cfg.Name, convey.ShouldEqual, "mocked")
+		})
+		PatchConvey("call arg", func() {
+			Mock(effectWalk).Return(nil).CallArg(1, "a.txt", 1).CallArg(1, "b.txt", 2).Build()
+			var paths []string
+			err := effectWalk("root", func(path string, depth int) error {
+				paths = append(paths, fmt.Sprintf("%s:%d", path, depth))
+				return nil
+			})
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(paths, convey.ShouldResemble, []string{"a.txt:1", "b.txt:2"})
+		})
+		PatchConvey("conversion", func() {
+			Mock(effectScan).SetArg(1, 8).SetArg(2, 1).Build()
+			var name string
+			var size int8
+			var ratio float64
+			effectScan(&name, &size, &ratio)
+			convey.So(size, convey.ShouldEqual, 8)
+			convey.So(ratio, convey.ShouldEqual, 1)
+
+			_, err := Mock(effectScan).SetArg(0, 65).TryBuild()
+			convey.So(errors.Is(err, ErrSignatureMismatch), convey.ShouldBeTrue)
+			_, err = Mock(effectScan).SetArg(1, 257).TryBuild()
+			convey.So(errors.Is(err, ErrSignatureMismatch), convey.ShouldBeTrue)
+			_, err = Mock(effectScan).SetArg(1, 1.5).TryBuild()
+			convey.So(errors.Is(err, ErrSignatureMismatch), convey.ShouldBeTrue)
+		})
+		PatchConvey("invalid", func() {
+			_, err := Mock(effectLoad).Return(nil).SetArg(2, nil).TryBuild()
+			convey.So(errors.Is(err, ErrSignatureMismatch), convey.ShouldBeTrue)
+			_, err = Mock(effectLoad).Return(nil).SetArg(0, "a").TryBuild()
+			convey.So(errors.Is(err, ErrSignatureMismatch), convey.ShouldBeTrue)
+			_, err = Mock(effectLoad).Return(nil).SetArg(1, 1).TryBuild()
+			convey.So(errors.Is(err, ErrSignatureMismatch), convey.ShouldBeTrue)
+			_, err = Mock(effectWalk).Return(nil).CallArg(1, "a.txt").TryBuild()
+			convey.So(errors.Is(err, ErrSignatureMismatch), convey.ShouldBeTrue)
+			_, err = Mock(effectWalk).Return(nil).CallArg(0).TryBuild()
+			convey.So(errors.Is(err, ErrSignatureMismatch), convey.ShouldBeTrue)
+		})
+	})
+}
@@ -19,11 +19,8 @@
 
 package mockey
 
-import "github.com/smartystreets/goconvey/convey"
-
 var (
 	mockGeneric    = Mock
 	misjudgeOpt    = []mockOptionFn{OptMethod}
-	remockResult   = convey.ShouldPanic
 	notMatchResult = "abc 123"
 )
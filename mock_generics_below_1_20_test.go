@@ -19,11 +19,8 @@
 
 package mockey
 
-import "github.com/smartystreets/goconvey/convey"
-
 var (
 	mockGeneric    = MockGeneric
 	misjudgeOpt    = []mockOptionFn{}
-	remockResult   = convey.ShouldNotPanic
 	notMatchResult = "MOCKED!"
 )
@@ -178,10 +178,9 @@ func TestGeneric(t *testing.T) {
 				convey.So(*res2, convey.ShouldEqual, "12345")
 			})
 			PatchConvey("re-mock", func() {
-				convey.So(func() {
-					mockGeneric((*genericMap[int32, *int]).Get).Return(&a).Build()
-					mockGeneric((*genericMap[int32, *int]).Get).Return(&a).Build()
-				}, remockResult)
+				mockGeneric((*genericMap[int32, *int]).Get).Return(new(int)).Build()
+				mockGeneric((*genericMap[int32, *int]).Get).Return(&a).Build()
+				convey.So(new(genericMap[int32, *int]).Get(1), convey.ShouldEqual, &a)
 			})
 		})
 		PatchConvey("not match", func() {
@@ -548,3 +547,18 @@ func GenericArgRetRunner[T any](name string) {
 	convey.So(func() { GenericsArgRet14(arg, arg, arg, arg, arg, arg, arg, arg, arg, arg, arg, arg, arg, arg) }, convey.ShouldPanic)
 	convey.So(func() { GenericsArg15(arg, arg, arg, arg, arg, arg, arg, arg, arg, arg, arg, arg, arg, arg, arg) }, convey.ShouldPanic)
 }
+
+func TestGenericWhenArgs(t *testing.T) {
+	PatchConvey("generic when args", t, func() {
+		PatchConvey("func", func() {
+			mockGeneric(sum[int]).WhenArgs(Eq(1), Any()).Return(999).Build()
+			convey.So(sum[int](1, 2), convey.ShouldEqual, 999)
+			convey.So(sum[int](2, 2), convey.ShouldEqual, 4)
+		})
+		PatchConvey("method", func() {
+			mockGeneric((*generic[int]).Value3).WhenArgs(Field("a", Eq(1)), Eq(2)).Return("mocked").Build()
+			convey.So((&generic[int]{a: 1}).Value3(2), convey.ShouldEqual, "mocked")
+			convey.So((&generic[int]{a: 2}).Value3(2), convey.ShouldEqual, "2 2")
+		})
+	})
+}
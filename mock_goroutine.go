@@ -0,0 +1,137 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bytedance/mockey/internal/tool"
+)
+
+// FilterGoRoutines makes the mock take effect in the goroutines according to filter and gIds:
+//   - Disable: all goroutines, gIds are ignored
+//   - Include: the goroutines in gIds
+//   - Exclude: the goroutines not in gIds
+//   - IncludeTree: the goroutines in gIds and their descendants, see IncludeCurrentGoRoutineTree
+func (builder *MockBuilder) FilterGoRoutines(filter FilterGoroutineType, gIds ...int64) *MockBuilder {
+	builder.filterGoroutine, builder.filterMode = goroutineFilterOf(filter, gIds), filter.String()
+	if builder.filterGoroutine == nil {
+		builder.filterMode = ""
+	}
+	return builder
+}
+
+// goroutineFilterOf returns the goroutine filter of FilterGoRoutines, nil means all goroutines
+func goroutineFilterOf(filter FilterGoroutineType, gIds []int64) func(gId int64) bool {
+	ids := make(map[int64]bool, len(gIds))
+	for _, id := range gIds {
+		ids[id] = true
+	}
+	switch filter {
+	case Include:
+		return func(gId int64) bool { return ids[gId] }
+	case Exclude:
+		return func(gId int64) bool { return !ids[gId] }
+	case IncludeTree:
+		return func(gId int64) bool {
+			for _, root := range gIds {
+				if tool.IsGoroutineDescendant(gId, root) {
+					return true
+				}
+			}
+			return false
+		}
+	default:
+		return nil
+	}
+}
+
+// FilterGoRoutineFunc makes the mock only take effect in the goroutines for which filter returns true. The filter is
+// called in the goroutine calling the target.
+//
+// For example:
+//
+//	Mock(Fun).FilterGoRoutineFunc(func(gId int64) bool { return workers[gId] }).Return("mocked").Build()
+func (builder *MockBuilder) FilterGoRoutineFunc(filter func(gId int64) bool) *MockBuilder {
+	builder.filterGoroutine, builder.filterMode = filter, ""
+	if filter != nil {
+		builder.filterMode = "func"
+	}
+	return builder
+}
+
+// FilterByLabel makes the mock only take effect in the goroutines carrying the pprof label key with value, which is
+// set by pprof.Do or pprof.SetGoroutineLabels.
+//
+// For example:
+//
+//	Mock(Fun).FilterByLabel("tenant", "a").Return("mocked").Build()
+//	pprof.Do(ctx, pprof.Labels("tenant", "a"), func(ctx context.Context) {
+//		Fun("a") // mocked
+//	})
+func (builder *MockBuilder) FilterByLabel(key, value string) *MockBuilder {
+	builder.FilterGoRoutineFunc(labelFilterOf(key, value))
+	builder.filterMode = fmt.Sprintf("label(%s=%s)", key, value)
+	return builder
+}
+
+func labelFilterOf(key, value string) func(gId int64) bool {
+	return func(int64) bool {
+		v, ok := tool.GetGoroutineLabel(key)
+		return ok && v == value
+	}
+}
+
+// FilterGoRoutineOpts makes the mock only take effect in the goroutines passing all opts, it's used by the packages
+// built on mockey to apply the goroutine options of their users, see GoroutineOpt. It does nothing without opts.
+//
+// For example, the mock takes effect in the current goroutine only if it carries the pprof label tenant=a:
+//
+//	Mock(Fun).FilterGoRoutineOpts(OptIncludeCurrentGoRoutine(), OptFilterByLabel("tenant", "a")).Return("mocked").Build()
+func (builder *MockBuilder) FilterGoRoutineOpts(opts ...GoroutineOpt) *MockBuilder {
+	opt := resolveGoroutineOpt(opts...)
+	if len(opt.filters) == 0 {
+		return builder
+	}
+	filters := opt.filters
+	builder.FilterGoRoutineFunc(func(gId int64) bool {
+		for _, filter := range filters {
+			if !filter(gId) {
+				return false
+			}
+		}
+		return true
+	})
+	builder.filterMode = strings.Join(opt.modes, "&")
+	return builder
+}
+
+func (filter FilterGoroutineType) String() string {
+	switch filter {
+	case Disable:
+		return "disable"
+	case Include:
+		return "include"
+	case Exclude:
+		return "exclude"
+	case IncludeTree:
+		return "include_tree"
+	default:
+		return fmt.Sprintf("FilterGoroutineType(%d)", int64(filter))
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"fmt"
+)
+
+type goroutineOption struct {
+	modes   []string
+	filters []func(gId int64) bool
+}
+
+// GoroutineOpt restricts the goroutines in which the mocks take effect like the goroutine filters of MockBuilder. It's
+// used by the packages built on mockey, such as clock and httpmock, to filter all the mocks they build. The options are
+// combined, so a goroutine must pass all of them, see MockBuilder.FilterGoRoutineOpts.
+type GoroutineOpt func(*goroutineOption)
+
+// OptIncludeCurrentGoRoutine is the option of MockBuilder.IncludeCurrentGoRoutine
+func OptIncludeCurrentGoRoutine() GoroutineOpt {
+	return OptFilterGoRoutines(Include, GetGoroutineId())
+}
+
+// OptExcludeCurrentGoRoutine is the option of MockBuilder.ExcludeCurrentGoRoutine
+func OptExcludeCurrentGoRoutine() GoroutineOpt {
+	return OptFilterGoRoutines(Exclude, GetGoroutineId())
+}
+
+// OptIncludeCurrentGoRoutineTree is the option of MockBuilder.IncludeCurrentGoRoutineTree
+func OptIncludeCurrentGoRoutineTree() GoroutineOpt {
+	return OptFilterGoRoutines(IncludeTree, GetGoroutineId())
+}
+
+// OptFilterGoRoutines is the option of MockBuilder.FilterGoRoutines
+func OptFilterGoRoutines(filter FilterGoroutineType, gIds ...int64) GoroutineOpt {
+	return withGoroutineFilter(filter.String(), goroutineFilterOf(filter, gIds))
+}
+
+// OptFilterGoRoutineFunc is the option of MockBuilder.FilterGoRoutineFunc
+func OptFilterGoRoutineFunc(filter func(gId int64) bool) GoroutineOpt {
+	return withGoroutineFilter("func", filter)
+}
+
+// OptFilterByLabel is the option of MockBuilder.FilterByLabel
+func OptFilterByLabel(key, value string) GoroutineOpt {
+	return withGoroutineFilter(fmt.Sprintf("label(%s=%s)", key, value), labelFilterOf(key, value))
+}
+
+func withGoroutineFilter(mode string, filter func(gId int64) bool) GoroutineOpt {
+	return func(opt *goroutineOption) {
+		if filter != nil {
+			opt.modes = append(opt.modes, mode)
+			opt.filters = append(opt.filters, filter)
+		}
+	}
+}
+
+func resolveGoroutineOpt(fn ...GoroutineOpt) *goroutineOption {
+	opt := &goroutineOption{}
+	for _, f := range fn {
+		f(opt)
+	}
+	return opt
+}
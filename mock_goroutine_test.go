@@ -0,0 +1,80 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestFilterGoRoutines(t *testing.T) {
+	PatchConvey("TestFilterGoRoutines", t, func() {
+		call := func() string {
+			res := make(chan string)
+			go func() { res <- Fun("a") }()
+			return <-res
+		}
+
+		PatchConvey("ids", func() {
+			mocker := Mock(Fun).FilterGoRoutines(Include, 0, GetGoroutineId()).Return("mocked").Build()
+			convey.So(Fun("a"), convey.ShouldEqual, "mocked")
+			convey.So(call(), convey.ShouldEqual, "a")
+
+			mocker.FilterGoRoutines(Exclude, 0, GetGoroutineId())
+			convey.So(Fun("a"), convey.ShouldEqual, "a")
+			convey.So(call(), convey.ShouldEqual, "mocked")
+
+			mocker.FilterGoRoutines(Disable)
+			convey.So(Fun("a"), convey.ShouldEqual, "mocked")
+			convey.So(call(), convey.ShouldEqual, "mocked")
+		})
+		PatchConvey("func", func() {
+			gid := GetGoroutineId()
+			Mock(Fun).FilterGoRoutineFunc(func(gId int64) bool { return gId != gid }).Return("mocked").Build()
+			convey.So(Fun("a"), convey.ShouldEqual, "a")
+			convey.So(call(), convey.ShouldEqual, "mocked")
+		})
+		PatchConvey("label", func() {
+			Mock(Fun).FilterByLabel("tenant", "a").Return("mocked").Build()
+			convey.So(Fun("a"), convey.ShouldEqual, "a")
+			pprof.Do(context.Background(), pprof.Labels("tenant", "a"), func(context.Context) {
+				convey.So(Fun("a"), convey.ShouldEqual, "mocked")
+				convey.So(call(), convey.ShouldEqual, "mocked")
+			})
+			pprof.Do(context.Background(), pprof.Labels("tenant", "b"), func(context.Context) {
+				convey.So(Fun("a"), convey.ShouldEqual, "a")
+			})
+			convey.So(Fun("a"), convey.ShouldEqual, "a")
+		})
+		PatchConvey("opts", func() {
+			Mock(Fun).FilterGoRoutineOpts(OptIncludeCurrentGoRoutine(), OptFilterByLabel("tenant", "a")).Return("mocked").Build()
+			convey.So(Fun("a"), convey.ShouldEqual, "a")
+			pprof.Do(context.Background(), pprof.Labels("tenant", "a"), func(context.Context) {
+				convey.So(Fun("a"), convey.ShouldEqual, "mocked")
+				convey.So(call(), convey.ShouldEqual, "a")
+			})
+			convey.So(ActiveMocks()[0].GoroutineFilter, convey.ShouldEqual, "include&label(tenant=a)")
+		})
+		PatchConvey("no opts", func() {
+			Mock(Fun).FilterGoRoutineOpts().Return("mocked").Build()
+			convey.So(call(), convey.ShouldEqual, "mocked")
+		})
+	})
+}
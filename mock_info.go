@@ -0,0 +1,109 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"sync/atomic"
+)
+
+// MockInfo describes an active mock, see ActiveMocks
+type MockInfo struct {
+	Name            string `json:"name"`                       // name of the target
+	Variable        bool   `json:"variable"`                   // whether the target is a variable mocked by MockValue
+	Generic         bool   `json:"generic"`                    // whether the target is a generic function or method
+	Depth           int    `json:"depth"`                      // depth of the scope, 0 means out of PatchConvey, PatchRun and PatchT
+	Caller          string `json:"caller"`                     // location where the mock is patched
+	Times           int    `json:"times"`                      // times the target is called, see Mocker.Times
+	MockTimes       int    `json:"mock_times"`                 // times the hook is called, see Mocker.MockTimes
+	Paused          bool   `json:"paused"`                     // whether the mock is paused, see Mocker.Pause
+	GoroutineFilter string `json:"goroutine_filter,omitempty"` // mode of the goroutine filter, empty means all goroutines
+	Conditions      int    `json:"conditions"`                 // count of the conditions
+}
+
+// ActiveMocks returns the descriptors of all patched mocks, including the ones of Mocker and MockerVar, in the order
+// of their scopes and creation.
+func ActiveMocks() []MockInfo {
+	gLock.Lock()
+	defer gLock.Unlock()
+	var infos []MockInfo
+	for _, scope := range gScopes {
+		depth := 0
+		for s := scope.parent; s != nil; s = s.parent {
+			depth++
+		}
+		for _, mocker := range scope.mockers {
+			info := mocker.info()
+			info.Depth = depth
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+// IsMocked returns whether the target is mocked, the target is a function mocked by Mock or the pointer to a variable
+// mocked by MockValue.
+func IsMocked(target interface{}) bool {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Func && v.Kind() != reflect.Ptr {
+		return false
+	}
+	key := v.Pointer()
+	gLock.Lock()
+	defer gLock.Unlock()
+	for _, scope := range gScopes {
+		if scope.lookup(key) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// DumpJSON writes the descriptors returned by ActiveMocks to w in JSON, which can be attached to the report of a
+// failed test.
+func DumpJSON(w io.Writer) error {
+	infos := ActiveMocks()
+	if infos == nil {
+		infos = []MockInfo{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(infos)
+}
+
+func (mocker *Mocker) info() MockInfo {
+	return MockInfo{
+		Name:            mocker.name(),
+		Generic:         mocker.builder.analyzer.IsGeneric(),
+		Caller:          mocker.caller().String(),
+		Times:           mocker.Times(),
+		MockTimes:       mocker.MockTimes(),
+		Paused:          atomic.LoadInt32(&mocker.paused) != 0,
+		GoroutineFilter: mocker.builder.filterMode,
+		Conditions:      len(mocker.builder.conditions),
+	}
+}
+
+func (mocker *MockerVar) info() MockInfo {
+	return MockInfo{
+		Name:     mocker.name(),
+		Variable: true,
+		Caller:   mocker.caller().String(),
+	}
+}
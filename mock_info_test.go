@@ -0,0 +1,73 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func infoFun(a string) string {
+	fmt.Println(a)
+	return a
+}
+
+func TestActiveMocks(t *testing.T) {
+	PatchConvey("TestActiveMocks", t, func() {
+		v := 1
+		MockValue(&v).To(2)
+		Mock(infoFun).ExcludeCurrentGoRoutine().When(func(a string) bool { return a == "a" }).Return("mocked").Build()
+		convey.So(infoFun("a"), convey.ShouldEqual, "a")
+
+		var fun, variable *MockInfo
+		infos := ActiveMocks()
+		for i := range infos {
+			if infos[i].Variable {
+				variable = &infos[i]
+			} else if strings.HasSuffix(infos[i].Name, "infoFun") {
+				fun = &infos[i]
+			}
+		}
+		convey.So(fun, convey.ShouldNotBeNil)
+		convey.So(fun.Depth, convey.ShouldEqual, 1)
+		convey.So(fun.Generic, convey.ShouldBeFalse)
+		convey.So(fun.Times, convey.ShouldEqual, 1)
+		convey.So(fun.MockTimes, convey.ShouldEqual, 0)
+		convey.So(fun.GoroutineFilter, convey.ShouldEqual, "exclude")
+		convey.So(fun.Conditions, convey.ShouldEqual, 1)
+		convey.So(variable, convey.ShouldNotBeNil)
+		convey.So(variable.Depth, convey.ShouldEqual, 1)
+
+		convey.So(IsMocked(infoFun), convey.ShouldBeTrue)
+		convey.So(IsMocked(&v), convey.ShouldBeTrue)
+		convey.So(IsMocked(Fun2), convey.ShouldBeFalse)
+
+		var buf bytes.Buffer
+		convey.So(DumpJSON(&buf), convey.ShouldBeNil)
+		var dumped []MockInfo
+		convey.So(json.Unmarshal(buf.Bytes(), &dumped), convey.ShouldBeNil)
+		convey.So(dumped, convey.ShouldResemble, infos)
+	})
+	if IsMocked(infoFun) {
+		t.Error("infoFun is still mocked")
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"reflect"
+
+	"github.com/bytedance/mockey/internal/monkey"
+)
+
+// mockLayers is the mockers patched on the same runtime target, the last one is the top layer. The target jumps into
+// the hook of the top layer, and the proxy of each layer jumps into the hook of the layer below, or the origin function
+// for the bottom layer. So the origin of a layer is the layer below.
+type mockLayers struct {
+	patch   *monkey.Patch
+	origin  reflect.Value // origin function, i.e. the proxy of the bottom layer
+	mockers []*Mocker
+}
+
+var gLayers = make(map[uintptr]*mockLayers) // layers of the patched targets, guarded by gLock
+
+// originOf returns the origin function of the target function, or the target itself if it is not patched. It is used
+// by the internals which should not be affected by the mocks, such as the timer of Delay.
+func originOf(target interface{}) interface{} {
+	gLock.Lock()
+	defer gLock.Unlock()
+	if layers, ok := gLayers[reflect.ValueOf(target).Pointer()]; ok {
+		return layers.origin.Interface()
+	}
+	return target
+}
+
+// pushLayer patches the mocker as the top layer of the runtime target, gLock must be held
+func (mocker *Mocker) pushLayer(runtimeTarget reflect.Value) {
+	base := runtimeTarget.Pointer()
+	layers, ok := gLayers[base]
+	if !ok {
+		patch := monkey.PatchValue(runtimeTarget, mocker.hook, mocker.proxy, mocker.builder.unsafe)
+		// copy the proxy function out of the pointer, an addressable value points to the pointer instead of the function
+		layers = &mockLayers{patch: patch, origin: reflect.ValueOf(mocker.proxy.Elem().Interface())}
+		gLayers[base] = layers
+	} else {
+		mocker.proxyCode = monkey.Link(mocker.proxy, layers.mockers[len(layers.mockers)-1].hook)
+		layers.patch.Redirect(mocker.hook)
+	}
+	layers.mockers = append(layers.mockers, mocker)
+	mocker.layers = layers
+}
+
+// replaceLayer makes the layer above the mocker, or the target if the mocker is the top layer, jump into the rebuilt
+// hook of the mocker
+func (mocker *Mocker) replaceLayer() {
+	gLock.Lock()
+	defer gLock.Unlock()
+	layers := mocker.layers
+	for i, m := range layers.mockers {
+		if m != mocker {
+			continue
+		}
+		if i == len(layers.mockers)-1 {
+			layers.patch.Redirect(mocker.hook)
+		} else {
+			monkey.Relink(layers.mockers[i+1].proxyCode, mocker.hook)
+		}
+		return
+	}
+}
+
+// popLayer unpatches the mocker and restores the layer below it, the layer above it is linked to the layer below
+func (mocker *Mocker) popLayer() {
+	gLock.Lock()
+	defer gLock.Unlock()
+	layers := mocker.layers
+	for i, m := range layers.mockers {
+		if m != mocker {
+			continue
+		}
+		below := layers.origin
+		if i > 0 {
+			below = layers.mockers[i-1].hook
+		}
+		switch {
+		case len(layers.mockers) == 1:
+			layers.patch.Unpatch()
+			delete(gLayers, layers.patch.Base())
+		case i == len(layers.mockers)-1:
+			layers.patch.Redirect(below)
+		default:
+			monkey.Relink(layers.mockers[i+1].proxyCode, below)
+		}
+		layers.mockers = append(layers.mockers[:i:i], layers.mockers[i+1:]...)
+		break
+	}
+	if mocker.proxyCode != nil {
+		monkey.Unlink(mocker.proxyCode)
+	}
+	mocker.layers, mocker.proxyCode = nil, nil
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func layerFun(a string) string {
+	fmt.Println(a)
+	return "ori:" + a
+}
+
+func TestLayeredMock(t *testing.T) {
+	PatchConvey("TestLayeredMock", t, func() {
+		base := Mock(layerFun).To(func(a string) string { return "base:" + a }).Build()
+
+		PatchConvey("refine in nested scope", func() {
+			origin := layerFun
+			top := Mock(layerFun).Origin(&origin).To(func(a string) string { return "top:" + origin(a) }).Build()
+			convey.So(layerFun("a"), convey.ShouldEqual, "top:base:a")
+			convey.So(top.Times(), convey.ShouldEqual, 1)
+			convey.So(base.Times(), convey.ShouldEqual, 1)
+		})
+		PatchConvey("unpatch top layer", func() {
+			top := Mock(layerFun).Return("top").Build()
+			convey.So(layerFun("a"), convey.ShouldEqual, "top")
+			top.UnPatch()
+			convey.So(layerFun("a"), convey.ShouldEqual, "base:a")
+		})
+		PatchConvey("unpatch middle layer in the same scope", func() {
+			middle := Mock(layerFun).To(func(a string) string { return "middle:" + a }).Build()
+			origin := layerFun
+			Mock(layerFun).Origin(&origin).To(func(a string) string { return "top:" + origin(a) }).Build()
+			convey.So(layerFun("a"), convey.ShouldEqual, "top:middle:a")
+			middle.UnPatch()
+			convey.So(layerFun("a"), convey.ShouldEqual, "top:base:a")
+		})
+		PatchConvey("unmatched calls go to the layer below", func() {
+			Mock(layerFun).When(func(a string) bool { return a == "b" }).Return("top").Build()
+			convey.So(layerFun("a"), convey.ShouldEqual, "base:a")
+			convey.So(layerFun("b"), convey.ShouldEqual, "top")
+		})
+
+		convey.So(layerFun("a"), convey.ShouldEqual, "base:a")
+	})
+	if r := layerFun("a"); r != "ori:a" {
+		t.Errorf("result = %s, expected = ori:a", r)
+	}
+}
+
+func TestLayeredMockUnpatchBottom(t *testing.T) {
+	PatchConvey("TestLayeredMockUnpatchBottom", t, func() {
+		base := Mock(layerFun).To(func(a string) string { return "base:" + a }).Build()
+
+		PatchConvey("unpatch bottom layer", func() {
+			origin := layerFun
+			Mock(layerFun).Origin(&origin).To(func(a string) string { return "top:" + origin(a) }).Build()
+			base.UnPatch()
+			convey.So(layerFun("a"), convey.ShouldEqual, "top:ori:a")
+		})
+		PatchConvey("unpatch middle and bottom layers", func() {
+			middle := Mock(layerFun).To(func(a string) string { return "middle:" + a }).Build()
+			origin := layerFun
+			top := Mock(layerFun).Origin(&origin).To(func(a string) string { return "top:" + origin(a) }).Build()
+			middle.UnPatch()
+			base.UnPatch()
+			convey.So(layerFun("a"), convey.ShouldEqual, "top:ori:a")
+			top.UnPatch()
+			convey.So(layerFun("a"), convey.ShouldEqual, "ori:a")
+		})
+	})
+	if r := layerFun("a"); r != "ori:a" {
+		t.Errorf("result = %s, expected = ori:a", r)
+	}
+}
+
+func TestLayeredMockReconfigure(t *testing.T) {
+	PatchConvey("TestLayeredMockReconfigure", t, func() {
+		base := Mock(layerFun).To(func(a string) string { return "base:" + a }).Build()
+		origin := layerFun
+		top := Mock(layerFun).Origin(&origin).To(func(a string) string { return "top:" + origin(a) }).Build()
+		convey.So(layerFun("a"), convey.ShouldEqual, "top:base:a")
+
+		PatchConvey("lower layer", func() {
+			base.Return("base2")
+			convey.So(layerFun("a"), convey.ShouldEqual, "top:base2")
+			convey.So(base.Times(), convey.ShouldEqual, 1)
+			base.To(func(a string) string { return "base3:" + a })
+			convey.So(layerFun("a"), convey.ShouldEqual, "top:base3:a")
+			base.ExcludeCurrentGoRoutine()
+			convey.So(layerFun("a"), convey.ShouldEqual, "top:ori:a")
+			top.UnPatch()
+			convey.So(layerFun("a"), convey.ShouldEqual, "ori:a")
+		})
+		PatchConvey("top layer", func() {
+			top.Return("top2")
+			convey.So(layerFun("a"), convey.ShouldEqual, "top2")
+			top.UnPatch()
+			convey.So(layerFun("a"), convey.ShouldEqual, "base:a")
+		})
+		PatchConvey("unpatched layer", func() {
+			base.UnPatch()
+			base.Return("base2")
+			convey.So(layerFun("a"), convey.ShouldEqual, "base2")
+		})
+	})
+	if r := layerFun("a"); r != "ori:a" {
+		t.Errorf("result = %s, expected = ori:a", r)
+	}
+}
@@ -0,0 +1,382 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/bytedance/mockey/internal/tool"
+)
+
+// Matcher matches a single argument of the target, see WhenArgs
+type Matcher interface {
+	// private make sure it is mockey private interface
+	private
+	// Matches reports whether the argument matches
+	Matches(arg interface{}) bool
+	// String describes the matcher, such as "Eq(1)"
+	String() string
+}
+
+type matcher struct {
+	Private // make sure it does implements mockey Matcher
+	desc    string
+	match   func(v reflect.Value) bool
+}
+
+func (m *matcher) Matches(arg interface{}) bool {
+	return m.match(reflect.ValueOf(arg))
+}
+
+func (m *matcher) String() string {
+	return m.desc
+}
+
+func newMatcher(desc string, match func(v reflect.Value) bool) Matcher {
+	return &matcher{desc: desc, match: match}
+}
+
+// Any matches any argument
+func Any() Matcher {
+	return newMatcher("Any()", func(reflect.Value) bool { return true })
+}
+
+// Eq matches the argument equal to expected. Numbers and strings are converted to the argument type before comparing
+// if the value is kept, so Eq(1) matches an int64 argument of 1 while Eq(1.5) matches no int. Uncomparable values,
+// including the comparable types holding uncomparable values in interfaces, are compared by reflect.DeepEqual.
+func Eq(expected interface{}) Matcher {
+	if expected == nil {
+		return newMatcher("Eq(nil)", isNil)
+	}
+	ev := reflect.ValueOf(expected)
+	return newMatcher(fmt.Sprintf("Eq(%#v)", expected), func(v reflect.Value) bool {
+		cv, ok := convertTo(ev, v)
+		if !ok {
+			return false
+		}
+		return equal(cv.Interface(), v.Interface())
+	})
+}
+
+// equal compares a and b by ==, or by reflect.DeepEqual if they are uncomparable. A comparable type may still hold
+// uncomparable values, such as a struct with an interface field holding a slice, so the panic of == is recovered too.
+func equal(a, b interface{}) (eq bool) {
+	if !reflect.TypeOf(a).Comparable() {
+		return reflect.DeepEqual(a, b)
+	}
+	defer func() {
+		if recover() != nil {
+			eq = reflect.DeepEqual(a, b)
+		}
+	}()
+	return a == b
+}
+
+// DeepEq matches the argument deeply equal to expected by reflect.DeepEqual. Numbers and strings are converted to the
+// argument type like Eq.
+func DeepEq(expected interface{}) Matcher {
+	if expected == nil {
+		return newMatcher("DeepEq(nil)", isNil)
+	}
+	ev := reflect.ValueOf(expected)
+	return newMatcher(fmt.Sprintf("DeepEq(%#v)", expected), func(v reflect.Value) bool {
+		cv, ok := convertTo(ev, v)
+		return ok && reflect.DeepEqual(cv.Interface(), v.Interface())
+	})
+}
+
+// Regex matches the argument of string, []byte, error or fmt.Stringer whose text matches the regular expression
+func Regex(expr string) Matcher {
+	re := regexp.MustCompile(expr)
+	return newMatcher(fmt.Sprintf("Regex(%q)", expr), func(v reflect.Value) bool {
+		if !v.IsValid() || isNil(v) {
+			return false
+		}
+		switch i := v.Interface().(type) {
+		case error:
+			return re.MatchString(i.Error())
+		case fmt.Stringer:
+			return re.MatchString(i.String())
+		}
+		if v.Kind() == reflect.String {
+			return re.MatchString(v.String())
+		}
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return re.Match(v.Bytes())
+		}
+		return false
+	})
+}
+
+// Nil matches nil argument, including nil pointer, map, slice, func, chan and interface
+func Nil() Matcher {
+	return newMatcher("Nil()", isNil)
+}
+
+// NotNil matches non-nil argument
+func NotNil() Matcher {
+	return newMatcher("NotNil()", func(v reflect.Value) bool { return !isNil(v) })
+}
+
+// Len matches the argument of array, chan, map, slice or string with length n
+func Len(n int) Matcher {
+	return newMatcher(fmt.Sprintf("Len(%d)", n), func(v reflect.Value) bool {
+		switch v.Kind() {
+		case reflect.Array, reflect.Chan, reflect.Map, reflect.Slice, reflect.String:
+			return v.Len() == n
+		default:
+			return false
+		}
+	})
+}
+
+// Field matches the argument whose field in path matches m. Path is separated by dot, each part of which is a struct
+// field name (unexported fields are supported), a string map key or a slice/array index. Pointers and interfaces are
+// dereferenced automatically. The argument does not match if the path cannot be resolved.
+//
+// For example:
+//
+//	Field("Header.Host", Eq("example.com"))
+//	Field("Items.0.ID", Eq(1))
+func Field(path string, m Matcher) Matcher {
+	parts := strings.Split(path, ".")
+	return newMatcher(fmt.Sprintf("Field(%q, %v)", path, m), func(v reflect.Value) bool {
+		for _, part := range parts {
+			var ok bool
+			if v, ok = fieldOf(v, part); !ok {
+				return false
+			}
+		}
+		return m.Matches(interfaceOf(v))
+	})
+}
+
+// AllOf matches the argument matching all the matchers
+func AllOf(matchers ...Matcher) Matcher {
+	return newMatcher(fmt.Sprintf("AllOf(%v)", joinMatchers(matchers)), func(v reflect.Value) bool {
+		for _, m := range matchers {
+			if !m.Matches(interfaceOf(v)) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// AnyOf matches the argument matching any of the matchers
+func AnyOf(matchers ...Matcher) Matcher {
+	return newMatcher(fmt.Sprintf("AnyOf(%v)", joinMatchers(matchers)), func(v reflect.Value) bool {
+		for _, m := range matchers {
+			if m.Matches(interfaceOf(v)) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Not matches the argument not matching m
+func Not(m Matcher) Matcher {
+	return newMatcher(fmt.Sprintf("Not(%v)", m), func(v reflect.Value) bool {
+		return !m.Matches(interfaceOf(v))
+	})
+}
+
+// Match matches the argument by a predicate function like func(T) bool. The argument does not match if it is not
+// assignable to T.
+//
+// For example:
+//
+//	Match(func(s string) bool { return strings.HasPrefix(s, "user_") })
+func Match(predicate interface{}) Matcher {
+	tool.AssertFunc(predicate)
+	pVal, pTyp := reflect.ValueOf(predicate), reflect.TypeOf(predicate)
+	tool.Assert(pTyp.NumIn() == 1 && !pTyp.IsVariadic(), "match predicate should have exactly one argument")
+	tool.Assert(pTyp.NumOut() == 1 && pTyp.Out(0).Kind() == reflect.Bool, "match predicate ret value not bool")
+	inType := pTyp.In(0)
+	return newMatcher(fmt.Sprintf("Match(%v)", pTyp), func(v reflect.Value) bool {
+		if !v.IsValid() {
+			v = reflect.Zero(inType)
+			if !isNil(v) {
+				return false
+			}
+		}
+		if !v.Type().AssignableTo(inType) {
+			return false
+		}
+		return pVal.Call([]reflect.Value{v})[0].Bool()
+	})
+}
+
+// WhenArgs declares the condition by matching each argument of the target with the matchers in order. The receiver
+// of a method can be optionally matched like When. The variadic arguments are matched as a whole slice, e.g. Len(2).
+//
+// For example:
+//
+//	func Fun(id int, name string, req *Req) string
+//	Mock(Fun).WhenArgs(Eq(1), Regex("^user_"), Field("Header.Host", Eq("example.com"))).Return("mocked").Build()
+func (builder *MockBuilder) WhenArgs(matchers ...Matcher) *MockBuilder {
+	return builder.do(func() error {
+		when, err := builder.matchersToWhen(matchers)
+		if err != nil {
+			return err
+		}
+		return builder.lastCondition().SetWhen(when)
+	})
+}
+
+// matchersToWhen compiles the matchers into a when function, which will be adapted by the analyzer like other
+// when functions
+func (builder *MockBuilder) matchersToWhen(matchers []Matcher) (interface{}, error) {
+	targetType := builder.analyzer.TargetType()
+	start := targetType.NumIn() - len(matchers)
+	if start != 0 && start != 1 {
+		return nil, newMockError(ErrSignatureMismatch, "matchers not match: target: %v, matcher count: %d", targetType, len(matchers))
+	}
+	var inTypes []reflect.Type
+	for i := start; i < targetType.NumIn(); i++ {
+		inTypes = append(inTypes, targetType.In(i))
+	}
+	whenType := reflect.FuncOf(inTypes, []reflect.Type{reflect.TypeOf(true)}, targetType.IsVariadic() && len(inTypes) > 0)
+	return reflect.MakeFunc(whenType, func(args []reflect.Value) []reflect.Value {
+		for i, m := range matchers {
+			if !m.Matches(interfaceOf(args[i])) {
+				return []reflect.Value{reflect.ValueOf(false)}
+			}
+		}
+		return []reflect.Value{reflect.ValueOf(true)}
+	}).Interface(), nil
+}
+
+func isNil(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface, reflect.UnsafePointer:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// convertTo converts expected to the type of v if they are of the same type, or both numbers or strings. A number is
+// only converted if its value is kept, e.g. 257 is not converted to int8 and 1.5 is not converted to int.
+func convertTo(expected, v reflect.Value) (reflect.Value, bool) {
+	if !v.IsValid() {
+		return expected, false
+	}
+	if expected.Type() == v.Type() {
+		return expected, true
+	}
+	if kindClass(expected.Kind()) != 0 && kindClass(expected.Kind()) == kindClass(v.Kind()) && expected.Type().ConvertibleTo(v.Type()) {
+		converted := expected.Convert(v.Type())
+		return converted, isLossless(expected, converted)
+	}
+	return expected, false
+}
+
+// isLossless reports whether converted has the same value as expected, i.e. it converts back to expected and keeps the
+// sign of expected
+func isLossless(expected, converted reflect.Value) bool {
+	if converted.Convert(expected.Type()).Interface() != expected.Interface() {
+		return false
+	}
+	return !(isSigned(expected.Kind()) && isUnsigned(converted.Kind()) && expected.Int() < 0) &&
+		!(isUnsigned(expected.Kind()) && isSigned(converted.Kind()) && converted.Int() < 0)
+}
+
+func isSigned(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUnsigned(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func kindClass(k reflect.Kind) int {
+	switch {
+	case k >= reflect.Int && k <= reflect.Float64:
+		return 1
+	case k == reflect.String:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// fieldOf gets the struct field, map value or slice element of v named by part, unexported struct fields are made
+// accessible
+func fieldOf(v reflect.Value, part string) (reflect.Value, bool) {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if !v.CanAddr() {
+			cp := reflect.New(v.Type()).Elem()
+			cp.Set(v)
+			v = cp
+		}
+		f := v.FieldByName(part)
+		if !f.IsValid() {
+			return f, false
+		}
+		if !f.CanInterface() {
+			// make unexported field accessible
+			f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+		}
+		return f, true
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return v, false
+		}
+		f := v.MapIndex(reflect.ValueOf(part).Convert(v.Type().Key()))
+		return f, f.IsValid()
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(part)
+		if err != nil || i < 0 || i >= v.Len() {
+			return v, false
+		}
+		return v.Index(i), true
+	default:
+		return v, false
+	}
+}
+
+func interfaceOf(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+func joinMatchers(matchers []Matcher) string {
+	desc := make([]string, len(matchers))
+	for i, m := range matchers {
+		desc[i] = m.String()
+	}
+	return strings.Join(desc, ", ")
+}
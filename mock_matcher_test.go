@@ -0,0 +1,118 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+type matcherReq struct {
+	ID     int
+	Header map[string]string
+	Items  []*matcherItem
+	inner  *matcherItem
+}
+
+type matcherItem struct {
+	name string
+}
+
+type matcherAny struct {
+	value interface{}
+}
+
+func matcherFun(id int64, name string, req *matcherReq) string {
+	return "origin"
+}
+
+func TestMatcher(t *testing.T) {
+	PatchConvey("TestMatcher", t, func() {
+		PatchConvey("basic", func() {
+			var nilPtr *matcherReq
+			convey.So(Any().Matches(nil), convey.ShouldBeTrue)
+			convey.So(Eq(1).Matches(int64(1)), convey.ShouldBeTrue)
+			convey.So(Eq(65).Matches("A"), convey.ShouldBeFalse)
+			convey.So(Eq(257).Matches(int8(1)), convey.ShouldBeFalse)
+			convey.So(Eq(1.5).Matches(1), convey.ShouldBeFalse)
+			convey.So(Eq(-1).Matches(uint(1<<64-1)), convey.ShouldBeFalse)
+			convey.So(Eq(uint64(1<<64-1)).Matches(-1), convey.ShouldBeFalse)
+			convey.So(Eq(2.0).Matches(int8(2)), convey.ShouldBeTrue)
+			convey.So(Eq([]int{1}).Matches([]int{1}), convey.ShouldBeTrue)
+			convey.So(Eq(matcherAny{value: []int{1}}).Matches(matcherAny{value: []int{1}}), convey.ShouldBeTrue)
+			convey.So(Eq(matcherAny{value: []int{1}}).Matches(matcherAny{value: []int{2}}), convey.ShouldBeFalse)
+			convey.So(Eq(matcherAny{value: 1}).Matches(matcherAny{value: 1}), convey.ShouldBeTrue)
+			convey.So(Eq(nil).Matches(nilPtr), convey.ShouldBeTrue)
+			convey.So(DeepEq(&matcherItem{name: "a"}).Matches(&matcherItem{name: "a"}), convey.ShouldBeTrue)
+			convey.So(Regex("^user_").Matches("user_1"), convey.ShouldBeTrue)
+			convey.So(Regex("^user_").Matches([]byte("admin")), convey.ShouldBeFalse)
+			convey.So(Regex("timeout").Matches(errors.New("read timeout")), convey.ShouldBeTrue)
+			convey.So(Nil().Matches(nilPtr), convey.ShouldBeTrue)
+			convey.So(NotNil().Matches(&matcherReq{}), convey.ShouldBeTrue)
+			convey.So(Len(2).Matches([]int{1, 2}), convey.ShouldBeTrue)
+			convey.So(Len(2).Matches(2), convey.ShouldBeFalse)
+			convey.So(AllOf(NotNil(), Len(1)).Matches("a"), convey.ShouldBeTrue)
+			convey.So(AnyOf(Eq(1), Eq(2)).Matches(3), convey.ShouldBeFalse)
+			convey.So(Not(Eq(1)).Matches(2), convey.ShouldBeTrue)
+			convey.So(Match(func(i int) bool { return i > 0 }).Matches(1), convey.ShouldBeTrue)
+			convey.So(Match(func(i int) bool { return i > 0 }).Matches("1"), convey.ShouldBeFalse)
+			convey.So(AnyOf(Eq(1), Len(2)).String(), convey.ShouldEqual, "AnyOf(Eq(1), Len(2))")
+		})
+		PatchConvey("field", func() {
+			req := &matcherReq{
+				ID:     1,
+				Header: map[string]string{"Host": "example.com"},
+				Items:  []*matcherItem{{name: "a"}},
+				inner:  &matcherItem{name: "b"},
+			}
+			convey.So(Field("ID", Eq(1)).Matches(req), convey.ShouldBeTrue)
+			convey.So(Field("Header.Host", Eq("example.com")).Matches(req), convey.ShouldBeTrue)
+			convey.So(Field("Items.0.name", Eq("a")).Matches(req), convey.ShouldBeTrue)
+			convey.So(Field("Items.1.name", Any()).Matches(req), convey.ShouldBeFalse)
+			convey.So(Field("inner.name", Eq("b")).Matches(*req), convey.ShouldBeTrue)
+			convey.So(Field("inner.name", Any()).Matches(&matcherReq{}), convey.ShouldBeFalse)
+			convey.So(Field("Missing", Any()).Matches(req), convey.ShouldBeFalse)
+		})
+		PatchConvey("when args", func() {
+			mocker := Mock(matcherFun).
+				WhenArgs(Eq(1), Regex("^user_"), Field("Header.Host", Eq("example.com"))).Return("mocked1").
+				WhenArgs(Any(), Any(), Nil()).Return("mocked2").
+				Build()
+			req := &matcherReq{Header: map[string]string{"Host": "example.com"}}
+			convey.So(matcherFun(1, "user_1", req), convey.ShouldEqual, "mocked1")
+			convey.So(matcherFun(2, "user_1", req), convey.ShouldEqual, "origin")
+			convey.So(matcherFun(2, "user_1", nil), convey.ShouldEqual, "mocked2")
+			convey.So(mocker.MockTimes(), convey.ShouldEqual, 2)
+		})
+		PatchConvey("receiver and variadic", func() {
+			c := &Class{}
+			Mock((*Class).VariantParam).WhenArgs(Eq("a"), Len(2)).Return("mocked1").Build()
+			convey.So(c.VariantParam("a", "b", "c"), convey.ShouldEqual, "mocked1")
+			convey.So(c.VariantParam("a", "b"), convey.ShouldEqual, "a")
+			UnPatchAll()
+
+			Mock((*Class).VariantParam).WhenArgs(Eq(c), Any(), Field("0", Eq("b"))).Return("mocked2").Build()
+			convey.So(c.VariantParam("a", "b"), convey.ShouldEqual, "mocked2")
+			convey.So((&Class{}).VariantParam("a", "b"), convey.ShouldEqual, "a")
+		})
+		PatchConvey("count not match", func() {
+			convey.So(func() { Mock(matcherFun).WhenArgs(Any()).Build() }, convey.ShouldPanic)
+		})
+	})
+}
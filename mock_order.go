@@ -0,0 +1,94 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// InOrder checks that the mockers are all called, and all calls of each mocker happen before the calls of the mockers
+// after it, by the sequence numbers of the call records, see CallRecord.Seq. If the check fails, it reports the failure
+// by t.Errorf with the observed interleaving of the calls. Only the recorded calls are checked, see
+// MockBuilder.RecordCalls.
+//
+// For example:
+//
+//	open := Mock(Open).Return(nil).Build()
+//	write := Mock(Write).Return(nil).Build()
+//	closer := Mock(Close).Return(nil).Build()
+//	Process()
+//	InOrder(t, open, write, closer) // Open, Write, Write, Close is fine, while Open, Write, Close, Write is not
+func InOrder(t testing.TB, mockers ...*Mocker) bool {
+	t.Helper()
+	if msg := orderFailure(mockers); msg != "" {
+		t.Errorf("mockey: %s", msg)
+		return false
+	}
+	return true
+}
+
+// Before reports whether the first call of mocker a happens before the first call of mocker b. It returns false if
+// either of them is not called.
+func Before(a, b *Mocker) bool {
+	callsA, callsB := a.Calls(), b.Calls()
+	if len(callsA) == 0 || len(callsB) == 0 {
+		return false
+	}
+	return callsA[0].Seq < callsB[0].Seq
+}
+
+type orderedCall struct {
+	index int // index of the mocker
+	seq   int64
+}
+
+func orderFailure(mockers []*Mocker) string {
+	var calls []orderedCall
+	for i, mocker := range mockers {
+		for _, call := range mocker.Calls() {
+			calls = append(calls, orderedCall{index: i, seq: call.Seq})
+		}
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].seq < calls[j].seq })
+
+	ok, next := true, 0
+	for _, call := range calls {
+		switch {
+		case call.index == next:
+			next++
+		case call.index != next-1:
+			ok = false
+		}
+	}
+	if ok && next == len(mockers) {
+		return ""
+	}
+
+	expected := make([]string, len(mockers))
+	for i, mocker := range mockers {
+		expected[i] = mocker.name()
+	}
+	observed := make([]string, len(calls))
+	for i, call := range calls {
+		observed[i] = mockers[call.index].name()
+	}
+	return fmt.Sprintf("calls expected in order: [%s], but observed: [%s]",
+		strings.Join(expected, ", "), strings.Join(observed, ", "))
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestInOrder(t *testing.T) {
+	PatchConvey("TestInOrder", t, func() {
+		PatchConvey("seq", func() {
+			mocker := Mock(Fun).Return("mocked").Build()
+			Fun("a")
+			Fun("b")
+			calls := mocker.Calls()
+			convey.So(calls[0].Seq, convey.ShouldBeLessThan, calls[1].Seq)
+		})
+		PatchConvey("in order", func() {
+			m1 := Mock(Fun1).Return(true).Build()
+			m2 := Mock(Fun2).Return(true).Build()
+			m3 := Mock(Fun).Return("mocked").Build()
+			Fun1()
+			Fun2()
+			Fun2()
+			Fun("a")
+
+			tb := &recordTB{TB: t}
+			convey.So(InOrder(tb, m1, m2, m3), convey.ShouldBeTrue)
+			convey.So(InOrder(tb, m1, m3), convey.ShouldBeTrue)
+			convey.So(tb.errors, convey.ShouldBeEmpty)
+			convey.So(Before(m1, m2), convey.ShouldBeTrue)
+			convey.So(Before(m3, m2), convey.ShouldBeFalse)
+		})
+		PatchConvey("out of order", func() {
+			m1 := Mock(Fun1).Return(true).Build()
+			m2 := Mock(Fun2).Return(true).Build()
+			Fun1()
+			Fun2()
+			Fun1()
+
+			tb := &recordTB{TB: t}
+			convey.So(InOrder(tb, m1, m2), convey.ShouldBeFalse)
+			convey.So(tb.errors, convey.ShouldHaveLength, 1)
+			convey.So(tb.errors[0], convey.ShouldEqual,
+				"mockey: calls expected in order: [github.com/bytedance/mockey.Fun1, github.com/bytedance/mockey.Fun2], "+
+					"but observed: [github.com/bytedance/mockey.Fun1, github.com/bytedance/mockey.Fun2, github.com/bytedance/mockey.Fun1]")
+			convey.So(Before(m1, m2), convey.ShouldBeTrue)
+		})
+		PatchConvey("not called", func() {
+			m1 := Mock(Fun1).Return(true).Build()
+			m2 := Mock(Fun2).Return(true).Build()
+			Fun1()
+
+			tb := &recordTB{TB: t}
+			convey.So(InOrder(tb, m1, m2), convey.ShouldBeFalse)
+			convey.So(tb.errors, convey.ShouldHaveLength, 1)
+			convey.So(Before(m1, m2), convey.ShouldBeFalse)
+		})
+	})
+}
@@ -17,8 +17,10 @@
 package mockey
 
 import (
+	"reflect"
 	"sync"
 
+	"github.com/bytedance/mockey/internal/fn"
 	"github.com/bytedance/mockey/internal/tool"
 )
 
@@ -33,14 +35,33 @@ type sequenceOpt interface {
 	SequenceOpt
 	Times(int) sequenceOpt
 	Then(...interface{}) sequenceOpt
+	ThenPanic(interface{}) sequenceOpt
+	ThenErr(error) sequenceOpt
+	ThenOrigin() sequenceOpt
+	ThenTo(interface{}) sequenceOpt
+	OnExhausted(ExhaustedOpt) sequenceOpt
 }
 
+// ExhaustedOpt decides what a sequence does after all its values are returned, see OnExhausted
+type ExhaustedOpt int
+
+const (
+	// Repeat restarts the sequence from the first value, which is the default
+	Repeat ExhaustedOpt = iota
+	// Fail panics when the target is called after the sequence is exhausted
+	Fail
+	// CallOrigin calls the origin function after the sequence is exhausted
+	CallOrigin
+)
+
 type sequence struct {
 	Private  // make sure it does implements mockey SequenceOpt
 	values   []*sequenceValue
 	curV     int // current value
 	curT     int // current value times
 	readLock sync.Mutex
+
+	onExhausted ExhaustedOpt
 }
 
 type sequenceValue struct {
@@ -50,18 +71,22 @@ type sequenceValue struct {
 
 func (s *sequence) GetNext() []interface{} {
 	s.readLock.Lock()
+	defer s.readLock.Unlock()
 	tool.Assert(len(s.values) > 0, "sequence is empty")
 
+	if s.curV >= len(s.values) {
+		tool.Assert(s.onExhausted == CallOrigin, "sequence is exhausted")
+		return []interface{}{originResult{}}
+	}
 	seqV := s.values[s.curV]
 	s.curT++
 	if s.curT >= seqV.t {
 		s.curT = 0
 		s.curV++
-		if s.curV >= len(s.values) {
+		if s.curV >= len(s.values) && s.onExhausted == Repeat {
 			s.curV = 0
 		}
 	}
-	s.readLock.Unlock()
 	return seqV.v
 }
 
@@ -73,6 +98,37 @@ func (s *sequence) Then(value ...interface{}) sequenceOpt {
 	return s
 }
 
+// ThenPanic appends a value which makes the target panic with value, see MockBuilder.Panic
+func (s *sequence) ThenPanic(value interface{}) sequenceOpt {
+	return s.Then(panicResult{value: value})
+}
+
+// ThenErr appends a value which makes the target return err, see MockBuilder.ReturnErr
+func (s *sequence) ThenErr(err error) sequenceOpt {
+	return s.Then(errResult{err: err})
+}
+
+// ThenOrigin appends a value which makes the target call the origin function
+func (s *sequence) ThenOrigin() sequenceOpt {
+	return s.Then(originResult{})
+}
+
+// ThenTo appends a value which makes the target call hook, see MockBuilder.To. The hook is checked when the mock is
+// built.
+func (s *sequence) ThenTo(hook interface{}) sequenceOpt {
+	return s.Then(&toResult{hook: hook})
+}
+
+// OnExhausted decides what the sequence does after all its values are returned, see Repeat, Fail and CallOrigin.
+//
+// For example, the following sequence fails twice and then calls the origin function:
+//
+//	Mock(Fun).Return(Sequence().ThenErr(io.EOF).Times(2).OnExhausted(CallOrigin)).Build()
+func (s *sequence) OnExhausted(opt ExhaustedOpt) sequenceOpt {
+	s.onExhausted = opt
+	return s
+}
+
 func (s *sequence) Times(t int) sequenceOpt {
 	tool.Assert(t > 0, "return times should more than 0")
 	s.values[len(s.values)-1].t = t
@@ -87,3 +143,97 @@ func Sequence(value ...interface{}) sequenceOpt {
 	seq.Then(value...)
 	return seq
 }
+
+type keyedSequence struct {
+	Private // make sure it does implements mockey SequenceOpt
+	keyFn   interface{}
+	keyType reflect.Type
+	seqs    map[interface{}]SequenceOpt // read only after created, each sequence has its own lock
+}
+
+var sequenceOptType = reflect.TypeOf((*SequenceOpt)(nil)).Elem()
+
+// SequenceBy returns a sequence which keeps a separate cursor for each key. The key of a call is returned by keyFn,
+// which has the same parameters as the target function like the condition hook of When, or only the leading ones of
+// them. The value is taken from the
+// sequence of the key in sequences, which is a map from the key to SequenceOpt. Calling the target with a key which is
+// not in sequences panics.
+//
+// For example, user 1 gets "A", "B", "C" while user 2 gets "X", "Y":
+//
+//	Mock(GetName).Return(SequenceBy(func(uid int64) int64 { return uid }, map[int64]SequenceOpt{
+//		1: Sequence("A").Then("B").Then("C"),
+//		2: Sequence("X").Then("Y"),
+//	})).Build()
+func SequenceBy(keyFn interface{}, sequences interface{}) SequenceOpt {
+	fnType := reflect.TypeOf(keyFn)
+	tool.Assert(fnType != nil && fnType.Kind() == reflect.Func && fnType.NumOut() == 1, "'%v' is not a key function", keyFn)
+	mapVal := reflect.ValueOf(sequences)
+	tool.Assert(mapVal.Kind() == reflect.Map && mapVal.Type().Elem().Implements(sequenceOptType), "'%v' is not a map of sequences", sequences)
+	keyType := mapVal.Type().Key()
+	tool.Assert(fnType.Out(0).ConvertibleTo(keyType), "key function returns %v, expected: %v", fnType.Out(0), keyType)
+
+	seqs := make(map[interface{}]SequenceOpt, mapVal.Len())
+	iter := mapVal.MapRange()
+	for iter.Next() {
+		seq, _ := iter.Value().Interface().(SequenceOpt)
+		tool.Assert(seq != nil, "sequence of key %v is nil", iter.Key())
+		seqs[iter.Key().Interface()] = seq
+	}
+	return &keyedSequence{keyFn: keyFn, keyType: keyType, seqs: seqs}
+}
+
+// adapter returns the adapter from the arguments of the runtime target to the arguments of keyFn. KeyFn may take only
+// the leading parameters of the target, so it is checked as if the rest parameters were appended, and the receiver of
+// a method may be omitted.
+func (s *keyedSequence) adapter(analyzer fn.Analyzer) func([]reflect.Value) []reflect.Value {
+	keyFnType, targetType := reflect.TypeOf(s.keyFn), analyzer.TargetType()
+	n := keyFnType.NumIn()
+	for skip := 0; !keyFnType.IsVariadic() && n+skip < targetType.NumIn() && skip <= 1; skip++ {
+		ins := make([]reflect.Type, 0, targetType.NumIn()-skip)
+		for i := 0; i < n; i++ {
+			ins = append(ins, keyFnType.In(i))
+		}
+		for i := n + skip; i < targetType.NumIn(); i++ {
+			ins = append(ins, targetType.In(i))
+		}
+		fullType := reflect.FuncOf(ins, []reflect.Type{keyFnType.Out(0)}, targetType.IsVariadic())
+		var full func([]reflect.Value) []reflect.Value
+		if catch(ErrSignatureMismatch, func() { full = analyzer.InputAdapter("key", fullType) }) == nil {
+			return func(args []reflect.Value) []reflect.Value { return full(args)[:n] }
+		}
+	}
+	return analyzer.InputAdapter("key", keyFnType)
+}
+
+// toResultsOf returns the values appended by ThenTo in the sequence, including the sequences of SequenceBy
+func toResultsOf(seq SequenceOpt) (res []*toResult) {
+	switch s := seq.(type) {
+	case *sequence:
+		for _, value := range s.values {
+			if len(value.v) == 1 {
+				if r, ok := value.v[0].(*toResult); ok {
+					res = append(res, r)
+				}
+			}
+		}
+	case *keyedSequence:
+		for _, sub := range s.seqs {
+			res = append(res, toResultsOf(sub)...)
+		}
+	}
+	return res
+}
+
+func (s *keyedSequence) GetNext() []interface{} {
+	tool.Assert(false, "sequence by key can only be used in Return")
+	return nil
+}
+
+// next returns the next value of the sequence of the key, keyArgs are the arguments of keyFn
+func (s *keyedSequence) next(keyArgs []reflect.Value) []interface{} {
+	key := tool.ReflectCall(reflect.ValueOf(s.keyFn), keyArgs)[0].Convert(s.keyType).Interface()
+	seq, ok := s.seqs[key]
+	tool.Assert(ok, "no sequence for key %v", key)
+	return seq.GetNext()
+}
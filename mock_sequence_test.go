@@ -17,8 +17,10 @@
 package mockey
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"strings"
 	"sync"
 	"testing"
 
@@ -120,5 +122,132 @@ func TestSequence(t *testing.T) {
 			Mock(fn).Return(Sequence()).Build()
 			convey.So(func() { fn() }, convey.ShouldPanicWith, "sequence is empty")
 		})
+
+		PatchConvey("exhausted", func() {
+			PatchConvey("fail", func() {
+				Mock(fn).Return(Sequence("Alice", 1).OnExhausted(Fail)).Build()
+				v1, _ := fn()
+				convey.So(v1, convey.ShouldEqual, "Alice")
+				convey.So(func() { fn() }, convey.ShouldPanicWith, "sequence is exhausted")
+			})
+			PatchConvey("call origin", func() {
+				Mock(fn).Return(Sequence("Alice", 1).OnExhausted(CallOrigin)).Build()
+				v1, _ := fn()
+				convey.So(v1, convey.ShouldEqual, "Alice")
+				v1, v2 := fn()
+				convey.So(v1, convey.ShouldEqual, "fn: not here")
+				convey.So(v2, convey.ShouldEqual, -1)
+			})
+			PatchConvey("repeat", func() {
+				Mock(fn).Return(Sequence("Alice", 1).Then("Bob", 2).OnExhausted(Repeat)).Build()
+				for _, expected := range []string{"Alice", "Bob", "Alice"} {
+					v1, _ := fn()
+					convey.So(v1, convey.ShouldEqual, expected)
+				}
+			})
+		})
+
+		PatchConvey("mixed actions", func() {
+			mocker := Mock(fn).Return(Sequence().
+				ThenPanic("boom").
+				ThenTo(func() (string, int) { return "to", 2 }).
+				ThenOrigin().Times(2).
+				OnExhausted(Fail)).Build()
+			convey.So(func() { fn() }, convey.ShouldPanicWith, "boom")
+			v1, v2 := fn()
+			convey.So(v1, convey.ShouldEqual, "to")
+			convey.So(v2, convey.ShouldEqual, 2)
+			for i := 0; i < 2; i++ {
+				v1, _ = fn()
+				convey.So(v1, convey.ShouldEqual, "fn: not here")
+			}
+			convey.So(func() { fn() }, convey.ShouldPanicWith, "sequence is exhausted")
+			convey.So(mocker.Times(), convey.ShouldEqual, 5)
+		})
+
+		PatchConvey("invalid to", func() {
+			_, err := Mock(fn).Return(Sequence("Alice", 1).ThenTo(func() string { return "to" })).TryBuild()
+			convey.So(errors.Is(err, ErrSignatureMismatch), convey.ShouldBeTrue)
+			_, err = Mock(fn).Return(SequenceBy(func() int { return 1 }, map[int]SequenceOpt{
+				1: Sequence().ThenTo(func(a int) (string, int) { return "to", a }),
+			})).TryBuild()
+			convey.So(errors.Is(err, ErrSignatureMismatch), convey.ShouldBeTrue)
+			mocker, err := Mock(fn).Return(SequenceBy(func() int { return 1 }, map[int]SequenceOpt{
+				1: Sequence().ThenTo(func() (string, int) { return "to", 1 }),
+			})).TryBuild()
+			convey.So(err, convey.ShouldBeNil)
+			v1, _ := fn()
+			convey.So(v1, convey.ShouldEqual, "to")
+			mocker.UnPatch()
+		})
+	})
+}
+
+type sequenceByStruct struct {
+	_ int
+}
+
+func (s *sequenceByStruct) Get(uid int64, name string) string {
+	panic("not here")
+}
+
+func TestSequenceBy(t *testing.T) {
+	PatchConvey("test sequence by", t, func() {
+		fn := func(uid int64, name string) string {
+			fmt.Println("original fn")
+			return "fn: not here"
+		}
+
+		PatchConvey("normal", func() {
+			Mock(fn).Return(SequenceBy(func(uid int64) int64 { return uid }, map[int64]SequenceOpt{
+				1: Sequence("A").Then("B").Then("C"),
+				2: Sequence("X").Then("Y").OnExhausted(CallOrigin),
+			})).Build()
+			convey.So(fn(1, ""), convey.ShouldEqual, "A")
+			convey.So(fn(2, ""), convey.ShouldEqual, "X")
+			convey.So(fn(1, ""), convey.ShouldEqual, "B")
+			convey.So(fn(2, ""), convey.ShouldEqual, "Y")
+			convey.So(fn(2, ""), convey.ShouldEqual, "fn: not here")
+			convey.So(fn(1, ""), convey.ShouldEqual, "C")
+			convey.So(func() { fn(3, "") }, convey.ShouldPanicWith, "no sequence for key 3")
+		})
+
+		PatchConvey("method", func() {
+			Mock((*sequenceByStruct).Get).Return(SequenceBy(func(uid int64) int64 { return uid }, map[int64]SequenceOpt{
+				1: Sequence("A").Then("B"),
+			})).Build()
+			s := &sequenceByStruct{}
+			convey.So(s.Get(1, ""), convey.ShouldEqual, "A")
+			convey.So(s.Get(1, ""), convey.ShouldEqual, "B")
+		})
+
+		PatchConvey("race", func(c convey.C) {
+			mocker := Mock(fn).Return(SequenceBy(func(uid int64, name string) string { return name }, map[string]SequenceOpt{
+				"a": Sequence("A").Times(10).OnExhausted(Fail),
+				"b": Sequence("B").Times(10).OnExhausted(Fail),
+			})).Build()
+			wg := sync.WaitGroup{}
+			for i := 0; i < 10; i++ {
+				for _, name := range []string{"a", "b"} {
+					wg.Add(1)
+					go func(name string) {
+						defer wg.Done()
+						c.So(fn(0, name), convey.ShouldEqual, strings.ToUpper(name))
+					}(name)
+				}
+			}
+			wg.Wait()
+			c.So(mocker.MockTimes(), convey.ShouldEqual, 20)
+		})
+
+		PatchConvey("invalid", func() {
+			convey.So(func() { SequenceBy(1, map[int64]SequenceOpt{}) }, convey.ShouldPanic)
+			convey.So(func() { SequenceBy(func(uid int64) int64 { return uid }, map[int64]string{}) }, convey.ShouldPanic)
+			convey.So(func() { SequenceBy(func(uid int64) string { return "" }, map[int64]SequenceOpt{}) }, convey.ShouldPanic)
+			_, err := Mock(fn).Return(SequenceBy(func(name bool) bool { return name }, map[bool]SequenceOpt{})).TryBuild()
+			convey.So(errors.Is(err, ErrSignatureMismatch), convey.ShouldBeTrue)
+			_, err = Mock(fn).Return(SequenceBy(func(uid int64, name, more string) string { return name }, map[string]SequenceOpt{})).TryBuild()
+			convey.So(errors.Is(err, ErrSignatureMismatch), convey.ShouldBeTrue)
+		})
 	})
 }
@@ -276,6 +276,29 @@ func TestFilterGoRoutine(t *testing.T) {
 	})
 }
 
+func TestFilterGoRoutineTree(t *testing.T) {
+	PatchConvey("filter go routine tree", t, func() {
+		mock := Mock(Fun).IncludeCurrentGoRoutineTree().Return("b").Build()
+		So(Fun("a"), ShouldEqual, "b")
+
+		results := make(chan string, 1)
+		Go(func() {
+			Go(func() { results <- Fun("a") })
+		})
+		So(<-results, ShouldEqual, "b")
+		So(mock.MockTimes(), ShouldEqual, 2)
+
+		// the parent is not in the tree of the child
+		Go(func() {
+			mock.IncludeCurrentGoRoutineTree()
+			results <- Fun("a")
+		})
+		So(<-results, ShouldEqual, "b")
+		So(Fun("a"), ShouldEqual, "a")
+		So(mock.MockTimes(), ShouldEqual, 1)
+	})
+}
+
 func TestResetPatch(t *testing.T) {
 	PatchConvey("test mock", t, func() {
 		PatchConvey("test to", func() {
@@ -338,6 +361,26 @@ func TestRePatch(t *testing.T) {
 	})
 }
 
+func TestPause(t *testing.T) {
+	PatchConvey("TestPause", t, func() {
+		mocker := Mock(Fun).Return("mocked").Build()
+		So(Fun("a"), ShouldEqual, "mocked")
+
+		mocker.Pause()
+		So(Fun("a"), ShouldEqual, "a")
+		So(Fun("a"), ShouldEqual, "a")
+		So(mocker.Times(), ShouldEqual, 1)
+		So(mocker.MockTimes(), ShouldEqual, 1)
+		So(mocker.Calls(), ShouldHaveLength, 1)
+
+		mocker.Resume()
+		So(Fun("a"), ShouldEqual, "mocked")
+		So(mocker.Times(), ShouldEqual, 2)
+		So(mocker.MockTimes(), ShouldEqual, 2)
+		So(mocker.Calls(), ShouldHaveLength, 2)
+	})
+}
+
 func TestMockUnsafe(t *testing.T) {
 	Convey("TestMockUnsafe", t, func() {
 		mock := MockUnsafe(ShortFun).To(func() { panic("in hook") }).Build()
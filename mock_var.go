@@ -27,6 +27,8 @@ type mockerInstance interface {
 	key() uintptr
 	name() string
 	unPatch()
+	unmetExpectation() string
+	info() MockInfo
 
 	caller() tool.CallerInfo
 }
@@ -73,9 +75,12 @@ func (mocker *MockerVar) Patch() *MockerVar {
 	defer mocker.lock.Unlock()
 
 	if !mocker.isPatched {
-		mocker.target.Set(mocker.hook)
+		err := addToGlobal(mocker, func() error {
+			mocker.target.Set(mocker.hook)
+			return nil
+		})
+		tool.Assert(err == nil, "%v", err)
 		mocker.isPatched = true
-		addToGlobal(mocker)
 
 		mocker.outerCaller = tool.OuterCaller()
 	}
@@ -114,6 +119,10 @@ func (mocker *MockerVar) unPatch() {
 	mocker.UnPatch()
 }
 
+func (mocker *MockerVar) unmetExpectation() string {
+	return ""
+}
+
 func (mocker *MockerVar) caller() tool.CallerInfo {
 	return mocker.outerCaller
 }
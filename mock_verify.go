@@ -0,0 +1,127 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bytedance/mockey/internal/tool"
+)
+
+// CountOpt is the expectation of call count, see Exactly, AtLeast, AtMost and Never
+type CountOpt interface {
+	// private make sure it is mockey private interface
+	private
+	// Match is used by mockey, don't use it if you don't know what it does
+	Match(times int) bool
+	// String describes the expected count, such as "exactly 2 times"
+	String() string
+}
+
+type count struct {
+	Private // make sure it does implements mockey CountOpt
+	min     int
+	max     int // negative means unlimited
+}
+
+func (c *count) Match(times int) bool {
+	return times >= c.min && (c.max < 0 || times <= c.max)
+}
+
+func (c *count) String() string {
+	switch {
+	case c.min == c.max:
+		return "exactly " + tool.TimesString(c.min)
+	case c.max < 0:
+		return "at least " + tool.TimesString(c.min)
+	case c.min == 0:
+		return "at most " + tool.TimesString(c.max)
+	default:
+		return fmt.Sprintf("between %d and %s", c.min, tool.TimesString(c.max))
+	}
+}
+
+// Exactly expects the target to be called exactly n times
+func Exactly(n int) CountOpt {
+	tool.Assert(n >= 0, "count should not be negative")
+	return &count{min: n, max: n}
+}
+
+// AtLeast expects the target to be called at least n times
+func AtLeast(n int) CountOpt {
+	tool.Assert(n >= 0, "count should not be negative")
+	return &count{min: n, max: -1}
+}
+
+// AtMost expects the target to be called at most n times
+func AtMost(n int) CountOpt {
+	tool.Assert(n >= 0, "count should not be negative")
+	return &count{min: 0, max: n}
+}
+
+// Once expects the target to be called exactly once
+func Once() CountOpt {
+	return Exactly(1)
+}
+
+// Never expects the target not to be called
+func Never() CountOpt {
+	return Exactly(0)
+}
+
+// Verify checks the times the target is called, see Times. If the check fails, it reports the failure by t.Errorf with
+// the target name and the location where the mocker is created.
+//
+// For example:
+//
+//	mocker := Mock(Fun).Return("mocked").Build()
+//	Fun("a")
+//	mocker.Verify(t, Exactly(1))
+func (mocker *Mocker) Verify(t testing.TB, opt CountOpt) bool {
+	t.Helper()
+	return mocker.verify(t, "called", mocker.Times(), opt)
+}
+
+// VerifyMock checks the times the mock hook is executed, see MockTimes. If the check fails, it reports the failure by
+// t.Errorf with the target name and the location where the mocker is created.
+func (mocker *Mocker) VerifyMock(t testing.TB, opt CountOpt) bool {
+	t.Helper()
+	return mocker.verify(t, "mocked", mocker.MockTimes(), opt)
+}
+
+func (mocker *Mocker) verify(t testing.TB, action string, times int, opt CountOpt) bool {
+	t.Helper()
+	if opt.Match(times) {
+		return true
+	}
+	t.Errorf("mockey: %s", mocker.countFailure(action, times, opt))
+	return false
+}
+
+func (mocker *Mocker) countFailure(action string, times int, opt CountOpt) string {
+	return tool.CountFailure(mocker.name(), action, opt, times, mocker.Times(), mocker.MockTimes(), mocker.caller())
+}
+
+// unmetExpectation returns the failure message if the expectation set by MockBuilder.Expect is not met
+func (mocker *Mocker) unmetExpectation() string {
+	opt := mocker.builder.expect
+	if opt == nil || opt.Match(mocker.Times()) {
+		return ""
+	}
+	return mocker.countFailure("called", mocker.Times(), opt)
+}
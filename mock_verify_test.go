@@ -0,0 +1,78 @@
+/*
+ * Copyright 2022 ByteDance Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mockey
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bytedance/mockey/internal/tool"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// recordTB records the failures instead of failing the test
+type recordTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordTB) Helper() {}
+
+func (r *recordTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestVerify(t *testing.T) {
+	PatchConvey("TestVerify", t, func() {
+		PatchConvey("count", func() {
+			convey.So(Exactly(2).Match(2), convey.ShouldBeTrue)
+			convey.So(Exactly(2).Match(1), convey.ShouldBeFalse)
+			convey.So(AtLeast(2).Match(3), convey.ShouldBeTrue)
+			convey.So(AtLeast(2).Match(1), convey.ShouldBeFalse)
+			convey.So(AtMost(2).Match(0), convey.ShouldBeTrue)
+			convey.So(AtMost(2).Match(3), convey.ShouldBeFalse)
+			convey.So(Never().Match(0), convey.ShouldBeTrue)
+			convey.So(Never().Match(1), convey.ShouldBeFalse)
+			convey.So(Exactly(1).String(), convey.ShouldEqual, "exactly 1 time")
+			convey.So(AtLeast(2).String(), convey.ShouldEqual, "at least 2 times")
+			convey.So(func() { Exactly(-1) }, convey.ShouldPanicWith, "count should not be negative")
+		})
+		PatchConvey("success", func() {
+			mocker := Mock(Fun).When(func(a string) bool { return a == "a" }).Return("mocked").Build()
+			Fun("a")
+			Fun("b")
+			convey.So(mocker.Verify(t, Exactly(2)), convey.ShouldBeTrue)
+			convey.So(mocker.VerifyMock(t, Exactly(1)), convey.ShouldBeTrue)
+			convey.So(mocker.Verify(t, AtLeast(1)), convey.ShouldBeTrue)
+			convey.So(mocker.VerifyMock(t, AtMost(1)), convey.ShouldBeTrue)
+		})
+		PatchConvey("failure", func() {
+			mocker := Mock(Fun).Return("mocked").Build()
+			caller := tool.Caller()
+			caller.Line -= 1
+			Fun("a")
+
+			tb := &recordTB{TB: t}
+			convey.So(mocker.Verify(tb, Never()), convey.ShouldBeFalse)
+			convey.So(tb.errors, convey.ShouldHaveLength, 1)
+			convey.So(tb.errors[0], convey.ShouldContainSubstring, "github.com/bytedance/mockey.Fun")
+			convey.So(tb.errors[0], convey.ShouldContainSubstring, "expected to be called exactly 0 times, but called 1 time")
+			convey.So(strings.HasSuffix(tb.errors[0], caller.String()), convey.ShouldBeTrue)
+		})
+	})
+}
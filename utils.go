@@ -199,3 +199,13 @@ func unexportedMethodByName(instanceType reflect.Type, methodName string, opts *
 func GetGoroutineId() int64 {
 	return tool.GetGoroutineID()
 }
+
+// Go runs f in a new goroutine, which is tracked as a child of the current goroutine for IncludeCurrentGoRoutineTree.
+// It's needed before go1.21, or when the goroutines between the current one and the new one may exit early.
+func Go(f func()) {
+	parent := tool.GetGoroutineID()
+	go func() {
+		tool.TrackGoroutine(tool.GetGoroutineID(), parent)
+		f()
+	}()
+}